@@ -17,6 +17,7 @@
 package compose
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"runtime/debug"
@@ -155,7 +156,13 @@ func assignOne[T any](dest T, taken any, to string) (T, error) {
 	if len(to) == 0 { // assign to output directly
 		toSet := reflect.ValueOf(taken)
 		if !toSet.Type().AssignableTo(destValue.Type()) {
-			return dest, fmt.Errorf("mapping entire value has a mismatched type. from=%v, to=%v", toSet.Type(), destValue.Type())
+			return dest, &FieldMappingError{
+				Phase:        FieldMappingPhaseRuntime,
+				Kind:         FieldMappingTypeMismatch,
+				ExpectedType: destValue.Type(),
+				ActualType:   toSet.Type(),
+				Err:          fmt.Errorf("mapping entire value has a mismatched type. from=%v, to=%v", toSet.Type(), destValue.Type()),
+			}
 		}
 
 		destValue.Set(toSet)
@@ -165,6 +172,11 @@ func assignOne[T any](dest T, taken any, to string) (T, error) {
 
 	toSet := reflect.ValueOf(taken)
 
+	// a map has no static field layout, so its key is never a dotted/
+	// indexed path to parse -- it's always the literal string to, exactly
+	// as it was before nested-path mappings existed. This also keeps a
+	// pre-existing map-keyed mapping whose key contains a literal '.' or
+	// '[' working unchanged.
 	if destValue.Kind() == reflect.Map {
 		key, err := checkAndExtractToMapKey(to, destValue, toSet)
 		if err != nil {
@@ -175,24 +187,46 @@ func assignOne[T any](dest T, taken any, to string) (T, error) {
 		return destValue.Interface().(T), nil
 	}
 
-	field, err := checkAndExtractToField(to, destValue, toSet)
+	segs, err := parsePath(to)
 	if err != nil {
 		return dest, err
 	}
 
-	field.Set(toSet)
-	return destValue.Interface().(T), nil
+	if len(segs) == 1 && segs[0].Kind != IndexSegment { // fast path for the common single-segment mapping
+		field, err := checkAndExtractToField(to, destValue, toSet)
+		if err != nil {
+			return dest, err
+		}
 
+		field.Set(toSet)
+		return destValue.Interface().(T), nil
+	}
+
+	if err = walkSet(destValue, segs, toSet); err != nil {
+		return dest, err
+	}
+
+	return destValue.Interface().(T), nil
 }
 
 func checkAndExtractFromField(fromField string, input reflect.Value) (reflect.Value, error) {
 	f := input.FieldByName(fromField)
 	if !f.IsValid() {
-		return reflect.Value{}, fmt.Errorf("field mapping from a struct field, but field not found. field=%v, inputType=%v", fromField, input.Type())
+		return reflect.Value{}, &FieldMappingError{
+			FromPath: []Segment{FieldSeg(fromField)},
+			Phase:    FieldMappingPhaseRuntime,
+			Kind:     FieldMappingNotFound,
+			Err:      fmt.Errorf("field mapping from a struct field, but field not found. field=%v, inputType=%v", fromField, input.Type()),
+		}
 	}
 
 	if !f.CanInterface() {
-		return reflect.Value{}, fmt.Errorf("field mapping from a struct field, but field not exported. field= %v, inputType=%v", fromField, input.Type())
+		return reflect.Value{}, &FieldMappingError{
+			FromPath: []Segment{FieldSeg(fromField)},
+			Phase:    FieldMappingPhaseRuntime,
+			Kind:     FieldMappingUnexported,
+			Err:      fmt.Errorf("field mapping from a struct field, but field not exported. field= %v, inputType=%v", fromField, input.Type()),
+		}
 	}
 
 	return f, nil
@@ -200,12 +234,22 @@ func checkAndExtractFromField(fromField string, input reflect.Value) (reflect.Va
 
 func checkAndExtractFromMapKey(fromMapKey string, input reflect.Value) (reflect.Value, error) {
 	if !reflect.TypeOf(fromMapKey).AssignableTo(input.Type().Key()) {
-		return reflect.Value{}, fmt.Errorf("field mapping from a map key, but input is not a map with string key, type=%v", input.Type())
+		return reflect.Value{}, &FieldMappingError{
+			FromPath: []Segment{KeySeg(fromMapKey)},
+			Phase:    FieldMappingPhaseRuntime,
+			Kind:     FieldMappingNotStructOrMap,
+			Err:      fmt.Errorf("field mapping from a map key, but input is not a map with string key, type=%v", input.Type()),
+		}
 	}
 
 	v := input.MapIndex(reflect.ValueOf(fromMapKey))
 	if !v.IsValid() {
-		return reflect.Value{}, fmt.Errorf("field mapping from a map key, but key not found in input. key=%s, inputType= %v", fromMapKey, input.Type())
+		return reflect.Value{}, &FieldMappingError{
+			FromPath: []Segment{KeySeg(fromMapKey)},
+			Phase:    FieldMappingPhaseRuntime,
+			Kind:     FieldMappingNotFound,
+			Err:      fmt.Errorf("field mapping from a map key, but key not found in input. key=%s, inputType= %v", fromMapKey, input.Type()),
+		}
 	}
 
 	return v, nil
@@ -216,9 +260,37 @@ func checkAndExtractFieldType(field string, typ reflect.Type) (reflect.Type, err
 		return typ, nil
 	}
 
+	// a map has no static field layout, so field is never a dotted/indexed
+	// path to parse -- it's always the literal map key, exactly as it was
+	// before nested-path mappings existed. This also keeps a pre-existing
+	// map-keyed mapping whose key contains a literal '.' or '[' working
+	// unchanged.
+	if typ.Kind() == reflect.Map {
+		return checkAndExtractFieldSegmentType(field, typ)
+	}
+
+	segs, err := parsePath(field)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(segs) == 1 && segs[0].Kind != IndexSegment { // fast path for the common single-segment mapping
+		return checkAndExtractFieldSegmentType(segs[0].Name, typ)
+	}
+
+	return checkAndExtractPathType(segs, typ)
+}
+
+// checkAndExtractFieldSegmentType is the original single-field static check,
+// kept as the fast path for the overwhelmingly common non-nested mapping.
+func checkAndExtractFieldSegmentType(field string, typ reflect.Type) (reflect.Type, error) {
 	if typ.Kind() == reflect.Map {
 		if typ.Key() != strType {
-			return nil, fmt.Errorf("type[%v] is not a map with string key", typ)
+			return nil, &FieldMappingError{
+				Phase: FieldMappingPhaseStatic,
+				Kind:  FieldMappingNotStructOrMap,
+				Err:   fmt.Errorf("type[%v] is not a map with string key", typ),
+			}
 		}
 
 		return typ.Elem(), nil
@@ -229,21 +301,102 @@ func checkAndExtractFieldType(field string, typ reflect.Type) (reflect.Type, err
 	}
 
 	if typ.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("type[%v] is not a struct", typ)
+		return nil, &FieldMappingError{
+			Phase: FieldMappingPhaseStatic,
+			Kind:  FieldMappingNotStructOrMap,
+			Err:   fmt.Errorf("type[%v] is not a struct", typ),
+		}
 	}
 
 	f, ok := typ.FieldByName(field)
 	if !ok {
-		return nil, fmt.Errorf("type[%v] has no field[%s]", typ, field)
+		return nil, &FieldMappingError{
+			FromPath: []Segment{FieldSeg(field)},
+			Phase:    FieldMappingPhaseStatic,
+			Kind:     FieldMappingNotFound,
+			Err:      fmt.Errorf("type[%v] has no field[%s]", typ, field),
+		}
 	}
 
 	if !f.IsExported() {
-		return nil, fmt.Errorf("type[%v] has an unexported field[%s]", typ.String(), field)
+		return nil, &FieldMappingError{
+			FromPath: []Segment{FieldSeg(field)},
+			Phase:    FieldMappingPhaseStatic,
+			Kind:     FieldMappingUnexported,
+			Err:      fmt.Errorf("type[%v] has an unexported field[%s]", typ.String(), field),
+		}
 	}
 
 	return f.Type, nil
 }
 
+// checkAndExtractPathType descends a nested field-mapping path at compile
+// time, so a typo or a type mismatch several levels deep is still caught
+// statically instead of surfacing only at runtime.
+func checkAndExtractPathType(segs []Segment, typ reflect.Type) (reflect.Type, error) {
+	cur := typ
+
+	for i, seg := range segs {
+		for cur.Kind() == reflect.Ptr {
+			cur = cur.Elem()
+		}
+
+		if seg.Kind == IndexSegment {
+			if cur.Kind() != reflect.Slice && cur.Kind() != reflect.Array {
+				return nil, &FieldMappingError{
+					FromPath: segs[:i+1],
+					Phase:    FieldMappingPhaseStatic,
+					Kind:     FieldMappingNotStructOrMap,
+					Err:      fmt.Errorf("type[%v] is not a slice or array, failing segment[%s]", cur, pathString(segs[:i+1])),
+				}
+			}
+			cur = cur.Elem()
+			continue
+		}
+
+		switch cur.Kind() {
+		case reflect.Map:
+			if cur.Key() != strType {
+				return nil, &FieldMappingError{
+					FromPath: segs[:i+1],
+					Phase:    FieldMappingPhaseStatic,
+					Kind:     FieldMappingNotStructOrMap,
+					Err:      fmt.Errorf("type[%v] is not a map with string key, failing segment[%s]", cur, pathString(segs[:i+1])),
+				}
+			}
+			cur = cur.Elem()
+		case reflect.Struct:
+			f, ok := cur.FieldByName(seg.Name)
+			if !ok {
+				return nil, &FieldMappingError{
+					FromPath: segs[:i+1],
+					Phase:    FieldMappingPhaseStatic,
+					Kind:     FieldMappingNotFound,
+					Err:      fmt.Errorf("type[%v] has no field[%s], failing segment[%s]", cur, seg.Name, pathString(segs[:i+1])),
+				}
+			}
+			if !f.IsExported() {
+				return nil, &FieldMappingError{
+					FromPath: segs[:i+1],
+					Phase:    FieldMappingPhaseStatic,
+					Kind:     FieldMappingUnexported,
+					Err:      fmt.Errorf("type[%v] has an unexported field[%s], failing segment[%s]", cur, seg.Name, pathString(segs[:i+1])),
+				}
+			}
+			cur = f.Type
+		default:
+			return nil, &FieldMappingError{
+				FromPath: segs[:i+1],
+				Phase:    FieldMappingPhaseStatic,
+				Kind:     FieldMappingNotStructOrMap,
+				Err:      fmt.Errorf("type[%v] is not a struct or map, failing segment[%s]", cur, pathString(segs[:i+1])),
+			}
+		}
+	}
+
+	return cur, nil
+}
+
 var strType = reflect.TypeOf("")
 
 func checkAndExtractToField(toField string, output, toSet reflect.Value) (reflect.Value, error) {
@@ -252,20 +405,41 @@ func checkAndExtractToField(toField string, output, toSet reflect.Value) (reflec
 	}
 
 	if output.Kind() != reflect.Struct {
-		return reflect.Value{}, fmt.Errorf("field mapping to a struct field but output is not a struct, type=%v", output.Type())
+		return reflect.Value{}, &FieldMappingError{
+			Phase: FieldMappingPhaseRuntime,
+			Kind:  FieldMappingNotStructOrMap,
+			Err:   fmt.Errorf("field mapping to a struct field but output is not a struct, type=%v", output.Type()),
+		}
 	}
 
 	field := output.FieldByName(toField)
 	if !field.IsValid() {
-		return reflect.Value{}, fmt.Errorf("field mapping to a struct field, but field not found. field=%v, outputType=%v", toField, output.Type())
+		return reflect.Value{}, &FieldMappingError{
+			ToPath: []Segment{FieldSeg(toField)},
+			Phase:  FieldMappingPhaseRuntime,
+			Kind:   FieldMappingNotFound,
+			Err:    fmt.Errorf("field mapping to a struct field, but field not found. field=%v, outputType=%v", toField, output.Type()),
+		}
 	}
 
 	if !field.CanSet() {
-		return reflect.Value{}, fmt.Errorf("field mapping to a struct field, but field not exported. field=%v, outputType=%v", toField, output.Type())
+		return reflect.Value{}, &FieldMappingError{
+			ToPath: []Segment{FieldSeg(toField)},
+			Phase:  FieldMappingPhaseRuntime,
+			Kind:   FieldMappingUnexported,
+			Err:    fmt.Errorf("field mapping to a struct field, but field not exported. field=%v, outputType=%v", toField, output.Type()),
+		}
 	}
 
 	if !toSet.Type().AssignableTo(field.Type()) {
-		return reflect.Value{}, fmt.Errorf("field mapping to a struct field, but field has a mismatched type. field=%s, from=%v, to=%v", toField, toSet.Type(), field.Type())
+		return reflect.Value{}, &FieldMappingError{
+			ToPath:       []Segment{FieldSeg(toField)},
+			Phase:        FieldMappingPhaseRuntime,
+			Kind:         FieldMappingTypeMismatch,
+			ExpectedType: field.Type(),
+			ActualType:   toSet.Type(),
+			Err:          fmt.Errorf("field mapping to a struct field, but field has a mismatched type. field=%s, from=%v, to=%v", toField, toSet.Type(), field.Type()),
+		}
 	}
 
 	return field, nil
@@ -273,15 +447,31 @@ func checkAndExtractToField(toField string, output, toSet reflect.Value) (reflec
 
 func checkAndExtractToMapKey(toMapKey string, output, toSet reflect.Value) (reflect.Value, error) {
 	if output.Kind() != reflect.Map {
-		return reflect.Value{}, fmt.Errorf("field mapping to a map key but output is not a map, type=%v", output.Type())
+		return reflect.Value{}, &FieldMappingError{
+			Phase: FieldMappingPhaseRuntime,
+			Kind:  FieldMappingNotStructOrMap,
+			Err:   fmt.Errorf("field mapping to a map key but output is not a map, type=%v", output.Type()),
+		}
 	}
 
 	if !reflect.TypeOf(toMapKey).AssignableTo(output.Type().Key()) {
-		return reflect.Value{}, fmt.Errorf("field mapping to a map key but output is not a map with string key, type=%v", output.Type())
+		return reflect.Value{}, &FieldMappingError{
+			ToPath: []Segment{KeySeg(toMapKey)},
+			Phase:  FieldMappingPhaseRuntime,
+			Kind:   FieldMappingNotStructOrMap,
+			Err:    fmt.Errorf("field mapping to a map key but output is not a map with string key, type=%v", output.Type()),
+		}
 	}
 
 	if !toSet.Type().AssignableTo(output.Type().Elem()) {
-		return reflect.Value{}, fmt.Errorf("field mapping to a map key but map value has a mismatched type. key=%s, from=%v, to=%v", toMapKey, toSet.Type(), output.Type().Elem())
+		return reflect.Value{}, &FieldMappingError{
+			ToPath:       []Segment{KeySeg(toMapKey)},
+			Phase:        FieldMappingPhaseRuntime,
+			Kind:         FieldMappingTypeMismatch,
+			ExpectedType: output.Type().Elem(),
+			ActualType:   toSet.Type(),
+			Err:          fmt.Errorf("field mapping to a map key but map value has a mismatched type. key=%s, from=%v, to=%v", toMapKey, toSet.Type(), output.Type().Elem()),
+		}
 	}
 
 	return reflect.ValueOf(toMapKey), nil
@@ -316,10 +506,31 @@ func takeOne(input any, from string) (taken any, err error) {
 
 	inputValue := reflect.ValueOf(input)
 
+	// a map has no static field layout, so its key is never a dotted/
+	// indexed path to parse -- it's always the literal string from, exactly
+	// as it was before nested-path mappings existed. This also keeps a
+	// pre-existing map-keyed mapping whose key contains a literal '.' or
+	// '[' working unchanged.
+	if inputValue.Kind() == reflect.Map {
+		f, err := checkAndExtractFromMapKey(from, inputValue)
+		if err != nil {
+			return nil, err
+		}
+		return f.Interface(), nil
+	}
+
+	segs, err := parsePath(from)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(segs) != 1 || segs[0].Kind == IndexSegment {
+		return takeOnePath(input, segs)
+	}
+
+	// fast path for the common single-segment mapping
 	var f reflect.Value
 	switch inputValue.Kind() {
-	case reflect.Map:
-		f, err = checkAndExtractFromMapKey(from, inputValue)
 	case reflect.Ptr:
 		inputValue = inputValue.Elem()
 		fallthrough
@@ -368,6 +579,36 @@ func validateStructOrMap(t reflect.Type) bool {
 	}
 }
 
+// asMappingStaticError annotates a static-check failure with the mapping's
+// node and which side (predecessor/from or successor/to) it occurred on,
+// preserving a structured *FieldMappingError produced deeper in the call
+// chain (e.g. by checkAndExtractPathType) instead of discarding it.
+func asMappingStaticError(err error, mapping *FieldMapping, fromSide bool) *FieldMappingError {
+	wrapped := fmt.Errorf("static check failed for mapping %s: %w", mapping, err)
+
+	var fme *FieldMappingError
+	if errors.As(err, &fme) {
+		cp := *fme
+		cp.FromNodeKey = mapping.fromNodeKey
+		cp.Err = wrapped
+		if fromSide {
+			cp.FromPath = fme.FromPath
+			cp.ToPath = nil
+		} else {
+			cp.ToPath = fme.FromPath
+			cp.FromPath = nil
+		}
+		return &cp
+	}
+
+	return &FieldMappingError{
+		FromNodeKey: mapping.fromNodeKey,
+		Phase:       FieldMappingPhaseStatic,
+		Kind:        FieldMappingNotStructOrMap,
+		Err:         wrapped,
+	}
+}
+
 func validateFieldMapping(predecessorType reflect.Type, successorType reflect.Type, mappings []*FieldMapping) (*handlerPair, error) {
 	var fieldCheckers = make(map[string]handlerPair)
 
@@ -384,22 +625,34 @@ func validateFieldMapping(predecessorType reflect.Type, successorType reflect.Ty
 
 	var (
 		predecessorFieldType, successorFieldType reflect.Type
-		err                                      error
+		err                                       error
+		staticErrs                                []*FieldMappingError
 	)
 
+	// collect every static-check failure in one pass instead of stopping
+	// at the first, so users see every wiring problem at once.
 	for _, mapping := range mappings {
 		predecessorFieldType, err = checkAndExtractFieldType(mapping.from, predecessorType)
 		if err != nil {
-			return nil, fmt.Errorf("static check failed for mapping %s: %w", mapping, err)
+			staticErrs = append(staticErrs, asMappingStaticError(err, mapping, true))
+			continue
 		}
 		successorFieldType, err = checkAndExtractFieldType(mapping.to, successorType)
 		if err != nil {
-			return nil, fmt.Errorf("static check failed for mapping %s: %w", mapping, err)
+			staticErrs = append(staticErrs, asMappingStaticError(err, mapping, false))
+			continue
 		}
 
 		at := checkAssignable(predecessorFieldType, successorFieldType)
 		if at == assignableTypeMustNot {
-			return nil, fmt.Errorf("static check failed for mapping %s, field[%v]-[%v] must not be assignable", mapping, predecessorFieldType, successorFieldType)
+			staticErrs = append(staticErrs, &FieldMappingError{
+				FromNodeKey:  mapping.fromNodeKey,
+				Phase:        FieldMappingPhaseStatic,
+				Kind:         FieldMappingTypeMismatch,
+				ExpectedType: successorFieldType,
+				ActualType:   predecessorFieldType,
+				Err:          fmt.Errorf("static check failed for mapping %s, field[%v]-[%v] must not be assignable", mapping, predecessorFieldType, successorFieldType),
+			})
 		} else if at == assignableTypeMay {
 			checker := func(a any) (any, error) {
 				trueInType := reflect.TypeOf(a)
@@ -417,6 +670,10 @@ func validateFieldMapping(predecessorType reflect.Type, successorType reflect.Ty
 		}
 	}
 
+	if len(staticErrs) > 0 {
+		return nil, &MultiFieldMappingError{Errs: staticErrs}
+	}
+
 	if len(fieldCheckers) == 0 {
 		return nil, nil
 	}
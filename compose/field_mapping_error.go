@@ -0,0 +1,143 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FieldMappingPhase identifies whether a FieldMappingError was caught by the
+// static check performed at Compile time, or surfaced while actually moving
+// data at runtime.
+type FieldMappingPhase string
+
+const (
+	FieldMappingPhaseStatic  FieldMappingPhase = "static"
+	FieldMappingPhaseRuntime FieldMappingPhase = "runtime"
+)
+
+// FieldMappingErrorKind classifies why a FieldMapping step failed.
+type FieldMappingErrorKind string
+
+const (
+	// FieldMappingNotFound: the path segment names a struct field or map
+	// key that doesn't exist on the value encountered.
+	FieldMappingNotFound FieldMappingErrorKind = "NotFound"
+	// FieldMappingUnexported: the path segment names a struct field that
+	// exists but is unexported.
+	FieldMappingUnexported FieldMappingErrorKind = "Unexported"
+	// FieldMappingTypeMismatch: the source value's type isn't assignable
+	// to the destination's type.
+	FieldMappingTypeMismatch FieldMappingErrorKind = "TypeMismatch"
+	// FieldMappingNotStructOrMap: a non-final path segment, or an entire
+	// mapping side, requires a struct or map value but found something else.
+	FieldMappingNotStructOrMap FieldMappingErrorKind = "NotStructOrMap"
+	// FieldMappingAmbiguous: an auto-mapping helper (e.g. MapByTag) found
+	// more than one predecessor field that could satisfy a successor field.
+	FieldMappingAmbiguous FieldMappingErrorKind = "Ambiguous"
+)
+
+// FieldMappingError is returned, wrapped, by FieldMapping-related failures,
+// carrying enough structure for callers to programmatically react to a
+// specific node, path, or failure kind instead of pattern-matching on an
+// error string.
+type FieldMappingError struct {
+	FromNodeKey string
+	ToNodeKey   string
+	FromPath    []Segment
+	ToPath      []Segment
+	Phase       FieldMappingPhase
+	Kind        FieldMappingErrorKind
+
+	ExpectedType reflect.Type
+	ActualType   reflect.Type
+
+	Err error
+}
+
+// Error implements the error interface.
+func (e *FieldMappingError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(string(e.Phase))
+	sb.WriteString(" field mapping error [")
+	sb.WriteString(string(e.Kind))
+	sb.WriteString("]")
+
+	if e.FromNodeKey != "" || len(e.FromPath) > 0 {
+		fmt.Fprintf(&sb, ", from node=%q path=%q", e.FromNodeKey, pathString(e.FromPath))
+	}
+	if e.ToNodeKey != "" || len(e.ToPath) > 0 {
+		fmt.Fprintf(&sb, ", to node=%q path=%q", e.ToNodeKey, pathString(e.ToPath))
+	}
+	if e.ExpectedType != nil || e.ActualType != nil {
+		fmt.Fprintf(&sb, ", expected=%v actual=%v", e.ExpectedType, e.ActualType)
+	}
+	if e.Err != nil {
+		fmt.Fprintf(&sb, ": %v", e.Err)
+	}
+
+	return sb.String()
+}
+
+// Unwrap allows errors.Is/As to reach the underlying cause, if any.
+func (e *FieldMappingError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches another *FieldMappingError by Kind, so callers can write
+// errors.Is(err, &compose.FieldMappingError{Kind: compose.FieldMappingNotFound}).
+// A target with an empty Phase matches any phase.
+func (e *FieldMappingError) Is(target error) bool {
+	t, ok := target.(*FieldMappingError)
+	if !ok {
+		return false
+	}
+	if t.Kind != "" && t.Kind != e.Kind {
+		return false
+	}
+	if t.Phase != "" && t.Phase != e.Phase {
+		return false
+	}
+	return true
+}
+
+// MultiFieldMappingError aggregates every static-check failure found in a
+// single Compile pass, instead of stopping at the first, so users see
+// every wiring problem at once.
+type MultiFieldMappingError struct {
+	Errs []*FieldMappingError
+}
+
+// Error implements the error interface.
+func (e *MultiFieldMappingError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, fe := range e.Errs {
+		msgs[i] = fe.Error()
+	}
+	return fmt.Sprintf("%d field mapping error(s):\n%s", len(e.Errs), strings.Join(msgs, "\n"))
+}
+
+// Unwrap allows errors.Is/As to reach any one of the aggregated errors.
+func (e *MultiFieldMappingError) Unwrap() []error {
+	errs := make([]error, len(e.Errs))
+	for i, fe := range e.Errs {
+		errs[i] = fe
+	}
+	return errs
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFieldMappingErrorIsMatchesByKind(t *testing.T) {
+	err := &FieldMappingError{
+		Phase: FieldMappingPhaseRuntime,
+		Kind:  FieldMappingNotFound,
+		Err:   fmt.Errorf("boom"),
+	}
+
+	if !errors.Is(err, &FieldMappingError{Kind: FieldMappingNotFound}) {
+		t.Error("expected errors.Is to match on Kind alone")
+	}
+	if errors.Is(err, &FieldMappingError{Kind: FieldMappingUnexported}) {
+		t.Error("expected errors.Is not to match a different Kind")
+	}
+	if !errors.Is(err, &FieldMappingError{Kind: FieldMappingNotFound, Phase: FieldMappingPhaseRuntime}) {
+		t.Error("expected errors.Is to match on Kind and Phase")
+	}
+	if errors.Is(err, &FieldMappingError{Kind: FieldMappingNotFound, Phase: FieldMappingPhaseStatic}) {
+		t.Error("expected errors.Is not to match a different Phase")
+	}
+}
+
+func TestFieldMappingErrorUnwrap(t *testing.T) {
+	cause := fmt.Errorf("underlying cause")
+	err := &FieldMappingError{Err: cause}
+
+	if !errors.Is(err, cause) {
+		t.Error("expected errors.Is to reach the wrapped cause via Unwrap")
+	}
+}
+
+func TestFieldMappingErrorAsFromWrappedError(t *testing.T) {
+	fme := &FieldMappingError{Kind: FieldMappingTypeMismatch}
+	wrapped := fmt.Errorf("context: %w", fme)
+
+	var got *FieldMappingError
+	if !errors.As(wrapped, &got) {
+		t.Fatal("expected errors.As to unwrap to the *FieldMappingError")
+	}
+	if got.Kind != FieldMappingTypeMismatch {
+		t.Errorf("Kind = %v, want %v", got.Kind, FieldMappingTypeMismatch)
+	}
+}
+
+func TestMultiFieldMappingErrorUnwrapsEach(t *testing.T) {
+	e1 := &FieldMappingError{Kind: FieldMappingNotFound, Err: fmt.Errorf("e1")}
+	e2 := &FieldMappingError{Kind: FieldMappingUnexported, Err: fmt.Errorf("e2")}
+	multi := &MultiFieldMappingError{Errs: []*FieldMappingError{e1, e2}}
+
+	if !errors.Is(multi, e1) || !errors.Is(multi, e2) {
+		t.Error("expected errors.Is to reach every aggregated error")
+	}
+
+	var fme *FieldMappingError
+	if !errors.As(multi, &fme) {
+		t.Fatal("expected errors.As to reach one of the aggregated errors")
+	}
+}
+
+func TestFieldMappingErrorErrorIncludesPaths(t *testing.T) {
+	err := &FieldMappingError{
+		Phase:    FieldMappingPhaseRuntime,
+		Kind:     FieldMappingTypeMismatch,
+		FromPath: []Segment{FieldSeg("a"), FieldSeg("b")},
+		ToPath:   []Segment{FieldSeg("c")},
+		Err:      fmt.Errorf("underlying"),
+	}
+
+	msg := err.Error()
+	if msg == "" {
+		t.Fatal("expected a non-empty error message")
+	}
+	for _, want := range []string{"a.b", "c", "underlying", "TypeMismatch"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Error() = %q, want it to contain %q", msg, want)
+		}
+	}
+}
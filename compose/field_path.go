@@ -0,0 +1,333 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SegmentKind identifies what a Segment addresses: a struct field, a map
+// key, or a slice/array index.
+type SegmentKind int
+
+const (
+	FieldSegment SegmentKind = iota
+	KeySegment
+	IndexSegment
+)
+
+// Segment is one step of a field-mapping path, e.g. the "profile" in
+// "user.profile.name" or the "0" in "messages[0].content".
+type Segment struct {
+	Kind  SegmentKind
+	Name  string
+	Index int
+}
+
+// FieldSeg creates a Segment addressing a struct field named name.
+func FieldSeg(name string) Segment { return Segment{Kind: FieldSegment, Name: name} }
+
+// KeySeg creates a Segment addressing a map key named key.
+func KeySeg(key string) Segment { return Segment{Kind: KeySegment, Name: key} }
+
+// IndexSeg creates a Segment addressing the i-th element of a slice or array.
+func IndexSeg(i int) Segment { return Segment{Kind: IndexSegment, Index: i} }
+
+// String returns the segment as it would appear within a path string.
+func (s Segment) String() string {
+	if s.Kind == IndexSegment {
+		return fmt.Sprintf("[%d]", s.Index)
+	}
+	return s.Name
+}
+
+// pathString joins segments back into the dotted, indexed form a user would
+// have written, for use in error messages.
+func pathString(segs []Segment) string {
+	var sb strings.Builder
+	for i, s := range segs {
+		if s.Kind != IndexSegment && i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(s.String())
+	}
+	return sb.String()
+}
+
+// parsePath splits a dotted, optionally indexed field-mapping path such as
+// "user.profile.name" or "messages[0].content" into a sequence of
+// Segments. Named segments parse to FieldSeg; the distinction from a map
+// key is made at walk time based on the actual reflect.Kind encountered,
+// since the parser has no type information to go on. A bare name with no
+// dots or brackets parses to a single FieldSeg, so single-segment callers
+// can keep using their existing fast path.
+//
+// Callers only reach parsePath once the mapping side in play has already
+// been confirmed not to be a map: a map key is always taken as a literal
+// string, never parsed as a path, so a pre-existing key containing a
+// literal '.' or '[' keeps resolving exactly as it did before nested paths
+// existed.
+func parsePath(path string) ([]Segment, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	var (
+		segs  []Segment
+		token strings.Builder
+	)
+
+	flush := func() {
+		if token.Len() > 0 {
+			segs = append(segs, FieldSeg(token.String()))
+			token.Reset()
+		}
+	}
+
+	for i := 0; i < len(path); {
+		switch c := path[i]; c {
+		case '.':
+			flush()
+			i++
+		case '[':
+			flush()
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("field path %q: unterminated '[' starting at position %d", path, i)
+			}
+			idxStr := path[i+1 : i+end]
+			idx, err := strconv.Atoi(idxStr)
+			if err != nil {
+				return nil, fmt.Errorf("field path %q: invalid index %q", path, idxStr)
+			}
+			segs = append(segs, IndexSeg(idx))
+			i += end + 1
+		default:
+			token.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+
+	return segs, nil
+}
+
+// descendGet reads the child of value addressed by seg, dereferencing
+// pointers along the way. The returned FieldMappingErrorKind classifies a
+// non-nil error at the point of failure, so a caller building a
+// *FieldMappingError never has to guess the Kind back out of the message.
+func descendGet(value reflect.Value, seg Segment) (reflect.Value, FieldMappingErrorKind, error) {
+	for value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+
+	if seg.Kind == IndexSegment {
+		switch value.Kind() {
+		case reflect.Slice, reflect.Array:
+			if seg.Index < 0 || seg.Index >= value.Len() {
+				return reflect.Value{}, FieldMappingNotFound, fmt.Errorf("index %d out of range, len=%d", seg.Index, value.Len())
+			}
+			return value.Index(seg.Index), "", nil
+		default:
+			return reflect.Value{}, FieldMappingNotStructOrMap, fmt.Errorf("indexed segment %v but value is not a slice or array, type=%v", seg, value.Type())
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Map:
+		v := value.MapIndex(reflect.ValueOf(seg.Name))
+		if !v.IsValid() {
+			return reflect.Value{}, FieldMappingNotFound, fmt.Errorf("key[%s] not found in map, type=%v", seg.Name, value.Type())
+		}
+		return v, "", nil
+	case reflect.Struct:
+		f := value.FieldByName(seg.Name)
+		if !f.IsValid() {
+			return reflect.Value{}, FieldMappingNotFound, fmt.Errorf("field[%s] not found in struct, type=%v", seg.Name, value.Type())
+		}
+		if !f.CanInterface() {
+			return reflect.Value{}, FieldMappingUnexported, fmt.Errorf("field[%s] is unexported, type=%v", seg.Name, value.Type())
+		}
+		return f, "", nil
+	default:
+		return reflect.Value{}, FieldMappingNotStructOrMap, fmt.Errorf("segment[%s] but value is not a struct or map, type=%v", seg.Name, value.Type())
+	}
+}
+
+// takeOnePath walks segs over input, reading each step in turn.
+func takeOnePath(input any, segs []Segment) (any, error) {
+	value := reflect.ValueOf(input)
+
+	for i, seg := range segs {
+		var (
+			kind FieldMappingErrorKind
+			err  error
+		)
+		value, kind, err = descendGet(value, seg)
+		if err != nil {
+			return nil, &FieldMappingError{
+				FromPath: segs[:i+1],
+				Phase:    FieldMappingPhaseRuntime,
+				Kind:     kind,
+				Err:      fmt.Errorf("field mapping from path[%s] failed at %s: %w", pathString(segs), pathString(segs[:i+1]), err),
+			}
+		}
+	}
+
+	return value.Interface(), nil
+}
+
+// walkSet sets taken at the end of path segs within dest, auto-instantiating
+// intermediate nil maps, nil pointers, and growing slices as needed so that
+// deep paths work even against zero-valued intermediates.
+func walkSet(dest reflect.Value, segs []Segment, taken reflect.Value) error {
+	cur := dest
+
+	for i, seg := range segs {
+		last := i == len(segs)-1
+
+		for cur.Kind() == reflect.Ptr {
+			if cur.IsNil() {
+				if !cur.CanSet() {
+					return &FieldMappingError{
+						ToPath: segs[:i+1],
+						Phase:  FieldMappingPhaseRuntime,
+						Kind:   FieldMappingNotStructOrMap,
+						Err:    fmt.Errorf("path[%s] failed at %s: cannot instantiate a nil, unaddressable pointer", pathString(segs), pathString(segs[:i+1])),
+					}
+				}
+				cur.Set(reflect.New(cur.Type().Elem()))
+			}
+			cur = cur.Elem()
+		}
+
+		if seg.Kind == IndexSegment {
+			if cur.Kind() != reflect.Slice {
+				return &FieldMappingError{
+					ToPath: segs[:i+1],
+					Phase:  FieldMappingPhaseRuntime,
+					Kind:   FieldMappingNotStructOrMap,
+					Err:    fmt.Errorf("path[%s] failed at %s: value is not a slice, type=%v", pathString(segs), pathString(segs[:i+1]), cur.Type()),
+				}
+			}
+			if seg.Index < 0 {
+				return &FieldMappingError{
+					ToPath: segs[:i+1],
+					Phase:  FieldMappingPhaseRuntime,
+					Kind:   FieldMappingNotFound,
+					Err:    fmt.Errorf("path[%s] failed at %s: negative index %d", pathString(segs), pathString(segs[:i+1]), seg.Index),
+				}
+			}
+			if seg.Index >= cur.Len() {
+				grown := reflect.MakeSlice(cur.Type(), seg.Index+1, seg.Index+1)
+				reflect.Copy(grown, cur)
+				cur.Set(grown)
+			}
+			next := cur.Index(seg.Index)
+			if last {
+				return setLeaf(next, taken, segs)
+			}
+			cur = next
+			continue
+		}
+
+		switch cur.Kind() {
+		case reflect.Map:
+			if cur.IsNil() {
+				cur.Set(reflect.MakeMap(cur.Type()))
+			}
+			key := reflect.ValueOf(seg.Name)
+
+			if last {
+				if !taken.Type().AssignableTo(cur.Type().Elem()) {
+					return &FieldMappingError{
+						ToPath:       segs[:i+1],
+						Phase:        FieldMappingPhaseRuntime,
+						Kind:         FieldMappingTypeMismatch,
+						ExpectedType: cur.Type().Elem(),
+						ActualType:   taken.Type(),
+						Err:          fmt.Errorf("path[%s] failed at %s: has a mismatched type. from=%v, to=%v", pathString(segs), pathString(segs[:i+1]), taken.Type(), cur.Type().Elem()),
+					}
+				}
+				cur.SetMapIndex(key, taken)
+				return nil
+			}
+
+			// map values aren't addressable, so materialize the element,
+			// recurse into it, then store the (possibly new) value back.
+			elemType := cur.Type().Elem()
+			next := reflect.New(elemType).Elem()
+			if existing := cur.MapIndex(key); existing.IsValid() {
+				next.Set(existing)
+			}
+			if err := walkSet(next, segs[i+1:], taken); err != nil {
+				return err
+			}
+			cur.SetMapIndex(key, next)
+			return nil
+		case reflect.Struct:
+			field := cur.FieldByName(seg.Name)
+			if !field.IsValid() {
+				return &FieldMappingError{
+					ToPath: segs[:i+1],
+					Phase:  FieldMappingPhaseRuntime,
+					Kind:   FieldMappingNotFound,
+					Err:    fmt.Errorf("path[%s] failed at %s: field not found in struct, type=%v", pathString(segs), pathString(segs[:i+1]), cur.Type()),
+				}
+			}
+			if !field.CanSet() {
+				return &FieldMappingError{
+					ToPath: segs[:i+1],
+					Phase:  FieldMappingPhaseRuntime,
+					Kind:   FieldMappingUnexported,
+					Err:    fmt.Errorf("path[%s] failed at %s: field unexported, type=%v", pathString(segs), pathString(segs[:i+1]), cur.Type()),
+				}
+			}
+			if last {
+				return setLeaf(field, taken, segs)
+			}
+			cur = field
+		default:
+			return &FieldMappingError{
+				ToPath: segs[:i+1],
+				Phase:  FieldMappingPhaseRuntime,
+				Kind:   FieldMappingNotStructOrMap,
+				Err:    fmt.Errorf("path[%s] failed at %s: value is not a struct or map, type=%v", pathString(segs), pathString(segs[:i+1]), cur.Type()),
+			}
+		}
+	}
+
+	return nil
+}
+
+func setLeaf(dest reflect.Value, taken reflect.Value, segs []Segment) error {
+	if !taken.Type().AssignableTo(dest.Type()) {
+		return &FieldMappingError{
+			ToPath:       segs,
+			Phase:        FieldMappingPhaseRuntime,
+			Kind:         FieldMappingTypeMismatch,
+			ExpectedType: dest.Type(),
+			ActualType:   taken.Type(),
+			Err:          fmt.Errorf("path[%s] has a mismatched type. from=%v, to=%v", pathString(segs), taken.Type(), dest.Type()),
+		}
+	}
+	dest.Set(taken)
+	return nil
+}
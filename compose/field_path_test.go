@@ -0,0 +1,280 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want []Segment
+	}{
+		{"", nil},
+		{"name", []Segment{FieldSeg("name")}},
+		{"user.profile.name", []Segment{FieldSeg("user"), FieldSeg("profile"), FieldSeg("name")}},
+		{"messages[0].content", []Segment{FieldSeg("messages"), IndexSeg(0), FieldSeg("content")}},
+		{"[2]", []Segment{IndexSeg(2)}},
+	}
+
+	for _, tt := range tests {
+		got, err := parsePath(tt.path)
+		if err != nil {
+			t.Fatalf("parsePath(%q): %v", tt.path, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parsePath(%q) = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParsePathUnterminatedIndex(t *testing.T) {
+	if _, err := parsePath("messages[0"); err == nil {
+		t.Fatal("expected an error for an unterminated '['")
+	}
+}
+
+type pathInner struct {
+	Name string
+}
+
+type pathOuter struct {
+	Inner   pathInner
+	Items   []pathInner
+	Mapping map[string]string
+	private string
+}
+
+func TestTakeOnePathNested(t *testing.T) {
+	in := pathOuter{Inner: pathInner{Name: "abc"}}
+	segs, err := parsePath("Inner.Name")
+	if err != nil {
+		t.Fatalf("parsePath: %v", err)
+	}
+
+	got, err := takeOnePath(in, segs)
+	if err != nil {
+		t.Fatalf("takeOnePath: %v", err)
+	}
+	if got != "abc" {
+		t.Errorf("takeOnePath = %v, want abc", got)
+	}
+}
+
+func TestTakeOnePathIndexed(t *testing.T) {
+	in := pathOuter{Items: []pathInner{{Name: "first"}, {Name: "second"}}}
+	segs, err := parsePath("Items[1].Name")
+	if err != nil {
+		t.Fatalf("parsePath: %v", err)
+	}
+
+	got, err := takeOnePath(in, segs)
+	if err != nil {
+		t.Fatalf("takeOnePath: %v", err)
+	}
+	if got != "second" {
+		t.Errorf("takeOnePath = %v, want second", got)
+	}
+}
+
+func TestTakeOnePathNotFoundSetsKind(t *testing.T) {
+	in := pathOuter{}
+	segs, err := parsePath("Missing")
+	if err != nil {
+		t.Fatalf("parsePath: %v", err)
+	}
+
+	_, err = takeOnePath(in, segs)
+	var fme *FieldMappingError
+	if !errors.As(err, &fme) {
+		t.Fatalf("expected a *FieldMappingError, got %v", err)
+	}
+	if fme.Kind != FieldMappingNotFound {
+		t.Errorf("Kind = %v, want %v", fme.Kind, FieldMappingNotFound)
+	}
+}
+
+func TestTakeOnePathUnexportedSetsKind(t *testing.T) {
+	in := pathOuter{private: "x"}
+	segs, err := parsePath("private")
+	if err != nil {
+		t.Fatalf("parsePath: %v", err)
+	}
+
+	_, err = takeOnePath(in, segs)
+	var fme *FieldMappingError
+	if !errors.As(err, &fme) {
+		t.Fatalf("expected a *FieldMappingError, got %v", err)
+	}
+	if fme.Kind != FieldMappingUnexported {
+		t.Errorf("Kind = %v, want %v", fme.Kind, FieldMappingUnexported)
+	}
+}
+
+func TestTakeOnePathIndexOutOfRangeSetsKind(t *testing.T) {
+	in := pathOuter{Items: []pathInner{{Name: "only"}}}
+	segs, err := parsePath("Items[5]")
+	if err != nil {
+		t.Fatalf("parsePath: %v", err)
+	}
+
+	_, err = takeOnePath(in, segs)
+	var fme *FieldMappingError
+	if !errors.As(err, &fme) {
+		t.Fatalf("expected a *FieldMappingError, got %v", err)
+	}
+	if fme.Kind != FieldMappingNotFound {
+		t.Errorf("Kind = %v, want %v", fme.Kind, FieldMappingNotFound)
+	}
+}
+
+func TestWalkSetNestedAutoVivifiesPointers(t *testing.T) {
+	type inner struct {
+		Name string
+	}
+	type outer struct {
+		Inner *inner
+	}
+
+	var dest outer
+	segs, err := parsePath("Inner.Name")
+	if err != nil {
+		t.Fatalf("parsePath: %v", err)
+	}
+
+	if err := walkSet(reflect.ValueOf(&dest).Elem(), segs, reflect.ValueOf("set")); err != nil {
+		t.Fatalf("walkSet: %v", err)
+	}
+	if dest.Inner == nil || dest.Inner.Name != "set" {
+		t.Errorf("dest = %+v, want Inner.Name = set", dest)
+	}
+}
+
+func TestWalkSetGrowsSlice(t *testing.T) {
+	type outer struct {
+		Items []string
+	}
+
+	var dest outer
+	segs, err := parsePath("Items[2]")
+	if err != nil {
+		t.Fatalf("parsePath: %v", err)
+	}
+
+	if err := walkSet(reflect.ValueOf(&dest).Elem(), segs, reflect.ValueOf("c")); err != nil {
+		t.Fatalf("walkSet: %v", err)
+	}
+	if len(dest.Items) != 3 || dest.Items[2] != "c" {
+		t.Errorf("dest.Items = %+v, want len 3 with index 2 = c", dest.Items)
+	}
+}
+
+// TestWalkSetNegativeIndexReturnsErrorInsteadOfPanicking is the regression
+// test for a negative index segment (accepted by parsePath's strconv.Atoi)
+// reaching reflect.Value.Index on the write side, which panics instead of
+// failing gracefully the way the read path (descendGet) already does.
+func TestWalkSetNegativeIndexReturnsErrorInsteadOfPanicking(t *testing.T) {
+	type outer struct {
+		Items []string
+	}
+
+	var dest outer
+	segs, err := parsePath("Items[-1]")
+	if err != nil {
+		t.Fatalf("parsePath: %v", err)
+	}
+
+	err = walkSet(reflect.ValueOf(&dest).Elem(), segs, reflect.ValueOf("c"))
+	if err == nil {
+		t.Fatal("expected an error for a negative index, not a panic or silent success")
+	}
+	var fme *FieldMappingError
+	if !errors.As(err, &fme) {
+		t.Fatalf("expected a *FieldMappingError, got %v", err)
+	}
+	if fme.Kind != FieldMappingNotFound {
+		t.Errorf("Kind = %v, want %v", fme.Kind, FieldMappingNotFound)
+	}
+}
+
+func TestWalkSetIntoMapValue(t *testing.T) {
+	type inner struct {
+		Name string
+	}
+	type outer struct {
+		M map[string]inner
+	}
+
+	var dest outer
+	segs, err := parsePath("M.key.Name")
+	if err != nil {
+		t.Fatalf("parsePath: %v", err)
+	}
+
+	if err := walkSet(reflect.ValueOf(&dest).Elem(), segs, reflect.ValueOf("v")); err != nil {
+		t.Fatalf("walkSet: %v", err)
+	}
+	if dest.M["key"].Name != "v" {
+		t.Errorf("dest.M[key].Name = %q, want v", dest.M["key"].Name)
+	}
+}
+
+// TestAssignOneMapDestinationUsesLiteralKey is the regression test for the
+// backward-compatibility fix: a map destination's key must never be parsed
+// as a dotted/indexed path, so a pre-existing mapping keyed by a literal
+// string containing '.' or '[' keeps resolving exactly as it did before
+// nested paths existed.
+func TestAssignOneMapDestinationUsesLiteralKey(t *testing.T) {
+	dest := map[string]string{}
+
+	got, err := assignOne(dest, "v1", "a.b[0]")
+	if err != nil {
+		t.Fatalf("assignOne: %v", err)
+	}
+	if got["a.b[0]"] != "v1" {
+		t.Errorf("expected literal key \"a.b[0]\" to be set, got %+v", got)
+	}
+}
+
+// TestTakeOneMapSourceUsesLiteralKey mirrors the destination-side regression
+// test for the source side of a mapping.
+func TestTakeOneMapSourceUsesLiteralKey(t *testing.T) {
+	in := map[string]string{"a.b[0]": "v1"}
+
+	got, err := takeOne(in, "a.b[0]")
+	if err != nil {
+		t.Fatalf("takeOne: %v", err)
+	}
+	if got != "v1" {
+		t.Errorf("takeOne = %v, want v1", got)
+	}
+}
+
+func TestCheckAndExtractFieldTypeMapUsesLiteralKey(t *testing.T) {
+	typ := reflect.TypeOf(map[string]int{})
+
+	elemType, err := checkAndExtractFieldType("a.b[0]", typ)
+	if err != nil {
+		t.Fatalf("checkAndExtractFieldType: %v", err)
+	}
+	if elemType.Kind() != reflect.Int {
+		t.Errorf("elemType = %v, want int", elemType)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/components/prompt"
 	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/schema"
 	"github.com/cloudwego/eino/utils/generic"
 )
 
@@ -37,8 +38,37 @@ type Mapping struct {
 	fromNodeKey string
 	from        string
 	to          string
+
+	// autoMode, when set, tells addEdgesWithMapping to expand this single
+	// Mapping into the concrete field-by-field Mappings produced by
+	// MapByTag/MapByName/MapAll/Select/Except, once both sides' types are
+	// known.
+	autoMode mappingAutoMode
+	autoTag  string
+
+	// selectFrom/selectTo back FromFields/ToFields/Select: the ordered
+	// predecessor and successor field names of a selection-set mapping.
+	selectFrom []string
+	selectTo   []string
+
+	// autoExcept backs Except: the successor field names to leave out of
+	// the by-name copy of the predecessor's output.
+	autoExcept []string
 }
 
+// mappingAutoMode selects how MapByTag/MapByName/MapAll auto-generate the
+// underlying field mappings.
+type mappingAutoMode int
+
+const (
+	mapAutoNone mappingAutoMode = iota
+	mapAutoByTag
+	mapAutoByName
+	mapAutoAll
+	mapAutoSelect
+	mapAutoExcept
+)
+
 func (m *Mapping) empty() bool {
 	return len(m.from) == 0 && len(m.to) == 0
 }
@@ -84,10 +114,98 @@ func NewMapping(fromNodeKey string) *Mapping {
 	return &Mapping{fromNodeKey: fromNodeKey}
 }
 
+// MapByTag auto-generates one field mapping per pair of fields, on the
+// predecessor's output type and this node's input type, whose struct tag
+// tag share the same value. It is resolved once both types are known, at
+// the same point a plain Mapping would be turned into a graph edge.
+func (m *Mapping) MapByTag(tag string) *Mapping {
+	m.autoMode = mapAutoByTag
+	m.autoTag = tag
+	return m
+}
+
+// MapByName auto-generates one field mapping per pair of exported fields,
+// on the predecessor's output type and this node's input type, that share
+// the same name.
+func (m *Mapping) MapByName() *Mapping {
+	m.autoMode = mapAutoByName
+	return m
+}
+
+// MapAll auto-generates one field mapping per exported field of this
+// node's input type, taken from the predecessor's output field of the same
+// name, and fails the static check if any successor field is left
+// unmapped or has no assignable counterpart on the predecessor side.
+func (m *Mapping) MapAll() *Mapping {
+	m.autoMode = mapAutoAll
+	return m
+}
+
+// FromFields selects multiple predecessor fields, by name, to be placed
+// into the successor fields set by a paired ToFields call, or, if ToFields
+// is never called, into successor fields of the same name. See Select for
+// pairing differently-named fields in one call.
+func (m *Mapping) FromFields(fields ...string) *Mapping {
+	m.selectFrom = fields
+	m.autoMode = mapAutoSelect
+	return m
+}
+
+// ToFields selects multiple successor fields, by name, to receive the
+// predecessor fields set by a paired FromFields call, or, if FromFields is
+// never called, from predecessor fields of the same name.
+func (m *Mapping) ToFields(fields ...string) *Mapping {
+	m.selectTo = fields
+	m.autoMode = mapAutoSelect
+	return m
+}
+
+// Select is FromFields and ToFields in one call: each pair is (predecessor
+// field, successor field), expressing "pick these fields from the
+// predecessor and place them into these fields of the successor" as a
+// single logical mapping, analogous to a GraphQL selection set. It expands
+// to one field mapping per pair, but the whole selection is validated
+// together, so a single bad field reports which selection it belongs to.
+func (m *Mapping) Select(pairs ...[2]string) *Mapping {
+	from := make([]string, len(pairs))
+	to := make([]string, len(pairs))
+	for i, p := range pairs {
+		from[i], to[i] = p[0], p[1]
+	}
+	m.selectFrom, m.selectTo = from, to
+	m.autoMode = mapAutoSelect
+	return m
+}
+
+// Except auto-generates a field mapping per exported successor field, by
+// name, from an assignable predecessor field of the same name, skipping
+// the fields named here: "the whole predecessor output, minus these
+// fields". Unlike MapAll, a successor field with no assignable predecessor
+// counterpart is left unmapped rather than failing the static check, since
+// Except only promises to copy whatever fits.
+func (m *Mapping) Except(fields ...string) *Mapping {
+	m.autoExcept = fields
+	m.autoMode = mapAutoExcept
+	return m
+}
+
 // WorkflowNode is the node of the Workflow.
 type WorkflowNode struct {
 	key    string
 	inputs []*Mapping
+
+	// inputType and outputType, when known, back the MapByTag/MapByName/
+	// MapAll auto-mapping helpers, which need both sides' reflect.Type to
+	// expand into concrete field mappings.
+	inputType  reflect.Type
+	outputType reflect.Type
+
+	// typ, component and addOpts record how this node was added, so that
+	// Workflow.Merge and Workflow.Include can replay the same AddXxxNode
+	// call against another Workflow or WorkflowFragment.
+	typ       string
+	component any
+	addOpts   []GraphAddNodeOpt
 }
 
 // Workflow is wrapper of Graph, replacing AddEdge with declaring Mapping between one node's output and current node's input.
@@ -98,13 +216,19 @@ type Workflow[I, O any] struct {
 	nodes map[string]*WorkflowNode
 	end   []*Mapping
 	err   error
+
+	// newGraphOpts is retained from NewWorkflow so Merge can rebuild gg from
+	// scratch when WithOverride replaces a node that's already been added
+	// once, since Graph itself has no primitive to remove or replace a node.
+	newGraphOpts []NewGraphOption
 }
 
 // NewWorkflow creates a new Workflow.
 func NewWorkflow[I, O any](opts ...NewGraphOption) *Workflow[I, O] {
 	wf := &Workflow[I, O]{
-		gg:    NewGraph[I, O](opts...),
-		nodes: make(map[string]*WorkflowNode),
+		gg:           NewGraph[I, O](opts...),
+		nodes:        make(map[string]*WorkflowNode),
+		newGraphOpts: opts,
 	}
 
 	wf.gg.cmp = ComponentOfWorkflow
@@ -131,7 +255,7 @@ func (wf *Workflow[I, O]) Compile(ctx context.Context, opts ...GraphCompileOptio
 }
 
 func (wf *Workflow[I, O]) AddChatModelNode(key string, chatModel model.ChatModel, opts ...GraphAddNodeOpt) *WorkflowNode {
-	node := &WorkflowNode{key: key}
+	node := &WorkflowNode{key: key, typ: "chatmodel", component: chatModel, addOpts: opts}
 	if wf.err != nil {
 		return node
 	}
@@ -143,11 +267,12 @@ func (wf *Workflow[I, O]) AddChatModelNode(key string, chatModel model.ChatModel
 		wf.err = err
 		return node
 	}
+	node.inputType, node.outputType = generic.TypeOf[[]*schema.Message](), generic.TypeOf[*schema.Message]()
 	return node
 }
 
 func (wf *Workflow[I, O]) AddChatTemplateNode(key string, chatTemplate prompt.ChatTemplate, opts ...GraphAddNodeOpt) *WorkflowNode {
-	node := &WorkflowNode{key: key}
+	node := &WorkflowNode{key: key, typ: "template", component: chatTemplate, addOpts: opts}
 	if wf.err != nil {
 		return node
 	}
@@ -160,11 +285,12 @@ func (wf *Workflow[I, O]) AddChatTemplateNode(key string, chatTemplate prompt.Ch
 		return node
 	}
 
+	node.inputType, node.outputType = generic.TypeOf[map[string]any](), generic.TypeOf[[]*schema.Message]()
 	return node
 }
 
 func (wf *Workflow[I, O]) AddToolsNode(key string, tools *ToolsNode, opts ...GraphAddNodeOpt) *WorkflowNode {
-	node := &WorkflowNode{key: key}
+	node := &WorkflowNode{key: key, typ: "tools", component: tools, addOpts: opts}
 	if wf.err != nil {
 		return node
 	}
@@ -176,11 +302,12 @@ func (wf *Workflow[I, O]) AddToolsNode(key string, tools *ToolsNode, opts ...Gra
 		wf.err = err
 		return node
 	}
+	node.inputType, node.outputType = generic.TypeOf[[]*schema.Message](), generic.TypeOf[[]*schema.Message]()
 	return node
 }
 
 func (wf *Workflow[I, O]) AddRetrieverNode(key string, retriever retriever.Retriever, opts ...GraphAddNodeOpt) *WorkflowNode {
-	node := &WorkflowNode{key: key}
+	node := &WorkflowNode{key: key, typ: "retriever", component: retriever, addOpts: opts}
 	if wf.err != nil {
 		return node
 	}
@@ -192,11 +319,12 @@ func (wf *Workflow[I, O]) AddRetrieverNode(key string, retriever retriever.Retri
 		wf.err = err
 		return node
 	}
+	node.inputType, node.outputType = generic.TypeOf[string](), generic.TypeOf[[]*schema.Document]()
 	return node
 }
 
 func (wf *Workflow[I, O]) AddEmbeddingNode(key string, embedding embedding.Embedder, opts ...GraphAddNodeOpt) *WorkflowNode {
-	node := &WorkflowNode{key: key}
+	node := &WorkflowNode{key: key, typ: "embedding", component: embedding, addOpts: opts}
 	if wf.err != nil {
 		return node
 	}
@@ -208,11 +336,12 @@ func (wf *Workflow[I, O]) AddEmbeddingNode(key string, embedding embedding.Embed
 		wf.err = err
 		return node
 	}
+	node.inputType, node.outputType = generic.TypeOf[[]string](), generic.TypeOf[[][]float64]()
 	return node
 }
 
 func (wf *Workflow[I, O]) AddIndexerNode(key string, indexer indexer.Indexer, opts ...GraphAddNodeOpt) *WorkflowNode {
-	node := &WorkflowNode{key: key}
+	node := &WorkflowNode{key: key, typ: "indexer", component: indexer, addOpts: opts}
 	if wf.err != nil {
 		return node
 	}
@@ -224,11 +353,12 @@ func (wf *Workflow[I, O]) AddIndexerNode(key string, indexer indexer.Indexer, op
 		wf.err = err
 		return node
 	}
+	node.inputType, node.outputType = generic.TypeOf[[]*schema.Document](), generic.TypeOf[[]string]()
 	return node
 }
 
 func (wf *Workflow[I, O]) AddLoaderNode(key string, loader document.Loader, opts ...GraphAddNodeOpt) *WorkflowNode {
-	node := &WorkflowNode{key: key}
+	node := &WorkflowNode{key: key, typ: "loader", component: loader, addOpts: opts}
 	if wf.err != nil {
 		return node
 	}
@@ -240,11 +370,12 @@ func (wf *Workflow[I, O]) AddLoaderNode(key string, loader document.Loader, opts
 		wf.err = err
 		return node
 	}
+	node.inputType, node.outputType = generic.TypeOf[document.Source](), generic.TypeOf[[]*schema.Document]()
 	return node
 }
 
 func (wf *Workflow[I, O]) AddDocumentTransformerNode(key string, transformer document.Transformer, opts ...GraphAddNodeOpt) *WorkflowNode {
-	node := &WorkflowNode{key: key}
+	node := &WorkflowNode{key: key, typ: "transformer", component: transformer, addOpts: opts}
 	if wf.err != nil {
 		return node
 	}
@@ -256,11 +387,12 @@ func (wf *Workflow[I, O]) AddDocumentTransformerNode(key string, transformer doc
 		wf.err = err
 		return node
 	}
+	node.inputType, node.outputType = generic.TypeOf[[]*schema.Document](), generic.TypeOf[[]*schema.Document]()
 	return node
 }
 
 func (wf *Workflow[I, O]) AddGraphNode(key string, graph AnyGraph, opts ...GraphAddNodeOpt) *WorkflowNode {
-	node := &WorkflowNode{key: key}
+	node := &WorkflowNode{key: key, typ: "graph", component: graph, addOpts: opts}
 	if wf.err != nil {
 		return node
 	}
@@ -272,11 +404,12 @@ func (wf *Workflow[I, O]) AddGraphNode(key string, graph AnyGraph, opts ...Graph
 		wf.err = err
 		return node
 	}
+	node.inputType, node.outputType = graph.inputType(), graph.outputType()
 	return node
 }
 
 func (wf *Workflow[I, O]) AddLambdaNode(key string, lambda *Lambda, opts ...GraphAddNodeOpt) *WorkflowNode {
-	node := &WorkflowNode{key: key}
+	node := &WorkflowNode{key: key, typ: "lambda", component: lambda, addOpts: opts}
 	if wf.err != nil {
 		return node
 	}
@@ -289,6 +422,7 @@ func (wf *Workflow[I, O]) AddLambdaNode(key string, lambda *Lambda, opts ...Grap
 		return node
 	}
 
+	node.inputType, node.outputType = lambda.inputType(), lambda.outputType()
 	return node
 }
 
@@ -332,13 +466,18 @@ func (wf *Workflow[I, O]) addEdgesWithMapping() (err error) {
 			return fmt.Errorf("workflow node = %s has no input", toNode)
 		}
 
-		toSet := make(map[string]bool, len(node.inputs))
+		inputs, err := wf.expandAutoMappings(node.inputs, node.inputType)
+		if err != nil {
+			return fmt.Errorf("workflow node = %s: %w", toNode, err)
+		}
+
+		toSet := make(map[string]bool, len(inputs))
 
-		fromNode2Mappings := make(map[string][]*Mapping, len(node.inputs))
-		for i := range node.inputs {
-			input := node.inputs[i]
+		fromNode2Mappings := make(map[string][]*Mapping, len(inputs))
+		for i := range inputs {
+			input := inputs[i]
 
-			if len(input.to) == 0 && len(node.inputs) > 1 {
+			if len(input.to) == 0 && len(inputs) > 1 {
 				return fmt.Errorf("workflow node = %s has multiple incoming mappings, one of them maps to entire input", toNode)
 			}
 
@@ -366,12 +505,17 @@ func (wf *Workflow[I, O]) addEdgesWithMapping() (err error) {
 		return errors.New("workflow END has no input mapping")
 	}
 
-	toSet := make(map[string]bool, len(wf.end))
-	fromNode2EndMappings := make(map[string][]*Mapping, len(wf.end))
-	for i := range wf.end {
-		input := wf.end[i]
+	endInputs, err := wf.expandAutoMappings(wf.end, wf.outputType())
+	if err != nil {
+		return fmt.Errorf("workflow END: %w", err)
+	}
+
+	toSet := make(map[string]bool, len(endInputs))
+	fromNode2EndMappings := make(map[string][]*Mapping, len(endInputs))
+	for i := range endInputs {
+		input := endInputs[i]
 
-		if len(input.to) == 0 && len(wf.end) > 1 {
+		if len(input.to) == 0 && len(endInputs) > 1 {
 			return fmt.Errorf("workflow node = %s has multiple incoming mappings, one of them maps to entire input", END)
 		}
 
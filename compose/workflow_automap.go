@@ -0,0 +1,431 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// expandAutoMappings replaces every MapByTag/MapByName/MapAll/Select/Except
+// Mapping in inputs with the concrete field-by-field Mappings it stands
+// for, now that toType (the successor's input type) is known. Plain
+// Mappings pass through unchanged. A field an auto Mapping would produce is
+// dropped if some other Mapping on this node - explicit or already
+// expanded - already targets the same successor field, so a user's
+// explicit Mapping always wins over an auto one.
+//
+// Every auto Mapping on the node is resolved, and every failure collected,
+// into the same *FieldMappingError/*MultiFieldMappingError types that
+// validateFieldMapping returns, so an ambiguous tag collision or an
+// unmapped MapAll field reports as a *FieldMappingError and a node with
+// several bad auto Mappings sees every one of them at once instead of
+// stopping at the first.
+func (wf *Workflow[I, O]) expandAutoMappings(inputs []*Mapping, toType reflect.Type) ([]*Mapping, error) {
+	hasAuto := false
+	for _, m := range inputs {
+		if m.autoMode != mapAutoNone {
+			hasAuto = true
+			break
+		}
+	}
+	if !hasAuto {
+		return inputs, nil
+	}
+
+	expanded := make([]*Mapping, 0, len(inputs))
+	seenTo := make(map[string]bool, len(inputs))
+	for _, m := range inputs {
+		if m.autoMode == mapAutoNone {
+			expanded = append(expanded, m)
+			seenTo[m.to] = true
+		}
+	}
+
+	var staticErrs []*FieldMappingError
+
+	for _, m := range inputs {
+		if m.autoMode == mapAutoNone {
+			continue
+		}
+
+		fromType, err := wf.nodeOutputType(m.fromNodeKey)
+		if err != nil {
+			staticErrs = append(staticErrs, asAutoMappingError(err, m))
+			continue
+		}
+
+		pairs, err := m.autoPairs(fromType, toType)
+		if err != nil {
+			staticErrs = append(staticErrs, asAutoMappingError(err, m))
+			continue
+		}
+
+		for _, p := range pairs {
+			if seenTo[p[1]] {
+				continue
+			}
+			seenTo[p[1]] = true
+			expanded = append(expanded, &Mapping{fromNodeKey: m.fromNodeKey, from: p[0], to: p[1]})
+		}
+	}
+
+	if len(staticErrs) > 0 {
+		return nil, &MultiFieldMappingError{Errs: staticErrs}
+	}
+
+	return expanded, nil
+}
+
+// asAutoMappingError annotates an auto-mapping failure with the Mapping's
+// node, preserving a structured *FieldMappingError produced deeper in the
+// call chain (e.g. by matchByTag) instead of discarding it, the same way
+// asMappingStaticError does for plain Mappings.
+func asAutoMappingError(err error, m *Mapping) *FieldMappingError {
+	wrapped := fmt.Errorf("static check failed for auto mapping %s: %w", m, err)
+
+	var fme *FieldMappingError
+	if errors.As(err, &fme) {
+		cp := *fme
+		cp.FromNodeKey = m.fromNodeKey
+		cp.Err = wrapped
+		return &cp
+	}
+
+	return &FieldMappingError{
+		FromNodeKey: m.fromNodeKey,
+		Phase:       FieldMappingPhaseStatic,
+		Kind:        FieldMappingNotStructOrMap,
+		Err:         wrapped,
+	}
+}
+
+// nodeOutputType returns the statically known output type of the node
+// identified by key, treating START as the workflow's own input.
+func (wf *Workflow[I, O]) nodeOutputType(key string) (reflect.Type, error) {
+	if key == START {
+		return wf.inputType(), nil
+	}
+
+	node, ok := wf.nodes[key]
+	if !ok {
+		return nil, &FieldMappingError{
+			Phase: FieldMappingPhaseStatic,
+			Kind:  FieldMappingNotFound,
+			Err:   fmt.Errorf("node = %s not found", key),
+		}
+	}
+	if node.outputType == nil {
+		return nil, &FieldMappingError{
+			Phase: FieldMappingPhaseStatic,
+			Kind:  FieldMappingNotStructOrMap,
+			Err:   fmt.Errorf("node = %s has no statically known output type, cannot auto-map", key),
+		}
+	}
+
+	return node.outputType, nil
+}
+
+// autoPairs resolves a single auto Mapping into its (from, to) field pairs.
+func (m *Mapping) autoPairs(fromType, toType reflect.Type) ([][2]string, error) {
+	switch m.autoMode {
+	case mapAutoByTag:
+		return matchByTag(m.autoTag, fromType, toType)
+	case mapAutoByName:
+		return matchByName(fromType, toType)
+	case mapAutoAll:
+		return matchAll(fromType, toType)
+	case mapAutoSelect:
+		return m.selectPairs(fromType, toType)
+	case mapAutoExcept:
+		return matchExcept(m.autoExcept, fromType, toType)
+	default:
+		return nil, nil
+	}
+}
+
+// selectPairs resolves a FromFields/ToFields/Select Mapping into its
+// (from, to) field pairs, validating every field in the selection together
+// so a single bad field names the whole selection as the failure.
+func (m *Mapping) selectPairs(fromType, toType reflect.Type) ([][2]string, error) {
+	from, to := m.selectFrom, m.selectTo
+	switch {
+	case len(from) == 0 && len(to) == 0:
+		return nil, &FieldMappingError{
+			Phase: FieldMappingPhaseStatic,
+			Kind:  FieldMappingNotStructOrMap,
+			Err:   fmt.Errorf("selection has neither FromFields nor ToFields set"),
+		}
+	case len(to) == 0:
+		to = from
+	case len(from) == 0:
+		from = to
+	case len(from) != len(to):
+		return nil, &FieldMappingError{
+			Phase: FieldMappingPhaseStatic,
+			Kind:  FieldMappingNotStructOrMap,
+			Err:   fmt.Errorf("selection {%s} and {%s} have different lengths", strings.Join(from, ","), strings.Join(to, ",")),
+		}
+	}
+
+	label := strings.Join(from, ",")
+
+	fromStruct, err := derefStructType(fromType)
+	if err != nil {
+		return nil, fmt.Errorf("selection {%s} of node %s failed: predecessor output is not a struct: %w", label, m.fromNodeKey, err)
+	}
+	toStruct, err := derefStructType(toType)
+	if err != nil {
+		return nil, fmt.Errorf("selection {%s} of node %s failed: successor input is not a struct: %w", label, m.fromNodeKey, err)
+	}
+
+	pairs := make([][2]string, len(from))
+	for i := range from {
+		ff, ok := fromStruct.FieldByName(from[i])
+		if !ok {
+			return nil, &FieldMappingError{
+				FromPath: []Segment{FieldSeg(from[i])},
+				Phase:    FieldMappingPhaseStatic,
+				Kind:     FieldMappingNotFound,
+				Err:      fmt.Errorf("selection {%s} of node %s failed: field %s not found", label, m.fromNodeKey, from[i]),
+			}
+		}
+		if !ff.IsExported() {
+			return nil, &FieldMappingError{
+				FromPath: []Segment{FieldSeg(from[i])},
+				Phase:    FieldMappingPhaseStatic,
+				Kind:     FieldMappingUnexported,
+				Err:      fmt.Errorf("selection {%s} of node %s failed: field %s unexported", label, m.fromNodeKey, from[i]),
+			}
+		}
+
+		tf, ok := toStruct.FieldByName(to[i])
+		if !ok {
+			return nil, &FieldMappingError{
+				ToPath: []Segment{FieldSeg(to[i])},
+				Phase:  FieldMappingPhaseStatic,
+				Kind:   FieldMappingNotFound,
+				Err:    fmt.Errorf("selection {%s} of node %s failed: field %s not found", label, m.fromNodeKey, to[i]),
+			}
+		}
+		if !tf.IsExported() {
+			return nil, &FieldMappingError{
+				ToPath: []Segment{FieldSeg(to[i])},
+				Phase:  FieldMappingPhaseStatic,
+				Kind:   FieldMappingUnexported,
+				Err:    fmt.Errorf("selection {%s} of node %s failed: field %s unexported", label, m.fromNodeKey, to[i]),
+			}
+		}
+		if !ff.Type.AssignableTo(tf.Type) {
+			return nil, &FieldMappingError{
+				FromPath:     []Segment{FieldSeg(from[i])},
+				ToPath:       []Segment{FieldSeg(to[i])},
+				Phase:        FieldMappingPhaseStatic,
+				Kind:         FieldMappingTypeMismatch,
+				ExpectedType: tf.Type,
+				ActualType:   ff.Type,
+				Err:          fmt.Errorf("selection {%s} of node %s failed: field %s has a mismatched type, from=%v to=%v", label, m.fromNodeKey, to[i], ff.Type, tf.Type),
+			}
+		}
+
+		pairs[i] = [2]string{from[i], to[i]}
+	}
+
+	return pairs, nil
+}
+
+// matchExcept pairs every exported successor field, by name, against an
+// assignable predecessor field of the same name, skipping any field named
+// in except. Unlike matchAll, a successor field with no assignable
+// predecessor counterpart is simply left unmapped rather than failing the
+// check, since Except only promises to copy whatever fits.
+func matchExcept(except []string, fromType, toType reflect.Type) ([][2]string, error) {
+	from, err := derefStructType(fromType)
+	if err != nil {
+		return nil, fmt.Errorf("Except requires a struct predecessor output: %w", err)
+	}
+	to, err := derefStructType(toType)
+	if err != nil {
+		return nil, fmt.Errorf("Except requires a struct successor input: %w", err)
+	}
+
+	skip := make(map[string]bool, len(except))
+	for _, f := range except {
+		skip[f] = true
+	}
+
+	var pairs [][2]string
+	for i := 0; i < to.NumField(); i++ {
+		f := to.Field(i)
+		if !f.IsExported() || skip[f.Name] {
+			continue
+		}
+		if ff, ok := from.FieldByName(f.Name); ok && ff.IsExported() && ff.Type.AssignableTo(f.Type) {
+			pairs = append(pairs, [2]string{f.Name, f.Name})
+		}
+	}
+
+	return pairs, nil
+}
+
+func derefStructType(t reflect.Type) (reflect.Type, error) {
+	if t == nil {
+		return nil, &FieldMappingError{
+			Phase: FieldMappingPhaseStatic,
+			Kind:  FieldMappingNotStructOrMap,
+			Err:   fmt.Errorf("type is unknown"),
+		}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, &FieldMappingError{
+			Phase: FieldMappingPhaseStatic,
+			Kind:  FieldMappingNotStructOrMap,
+			Err:   fmt.Errorf("type[%v] is not a struct", t),
+		}
+	}
+	return t, nil
+}
+
+// matchByTag pairs predecessor and successor struct fields whose tag tag
+// share the same value, failing on a predecessor-side tag collision.
+func matchByTag(tag string, fromType, toType reflect.Type) ([][2]string, error) {
+	from, err := derefStructType(fromType)
+	if err != nil {
+		return nil, fmt.Errorf("MapByTag requires a struct predecessor output: %w", err)
+	}
+	to, err := derefStructType(toType)
+	if err != nil {
+		return nil, fmt.Errorf("MapByTag requires a struct successor input: %w", err)
+	}
+
+	fromByTag := make(map[string]string, from.NumField())
+	for i := 0; i < from.NumField(); i++ {
+		f := from.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		v, ok := f.Tag.Lookup(tag)
+		if !ok || v == "" || v == "-" {
+			continue
+		}
+		if prev, dup := fromByTag[v]; dup {
+			return nil, &FieldMappingError{
+				FromPath: []Segment{FieldSeg(f.Name)},
+				Phase:    FieldMappingPhaseStatic,
+				Kind:     FieldMappingAmbiguous,
+				Err:      fmt.Errorf("ambiguous tag collision: predecessor fields %s and %s both have %s=%q", prev, f.Name, tag, v),
+			}
+		}
+		fromByTag[v] = f.Name
+	}
+
+	var pairs [][2]string
+	for i := 0; i < to.NumField(); i++ {
+		f := to.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		v, ok := f.Tag.Lookup(tag)
+		if !ok || v == "" || v == "-" {
+			continue
+		}
+		if fromField, ok := fromByTag[v]; ok {
+			pairs = append(pairs, [2]string{fromField, f.Name})
+		}
+	}
+
+	return pairs, nil
+}
+
+// matchByName pairs predecessor and successor struct fields that share the
+// same exported name.
+func matchByName(fromType, toType reflect.Type) ([][2]string, error) {
+	from, err := derefStructType(fromType)
+	if err != nil {
+		return nil, fmt.Errorf("MapByName requires a struct predecessor output: %w", err)
+	}
+	to, err := derefStructType(toType)
+	if err != nil {
+		return nil, fmt.Errorf("MapByName requires a struct successor input: %w", err)
+	}
+
+	var pairs [][2]string
+	for i := 0; i < to.NumField(); i++ {
+		f := to.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if ff, ok := from.FieldByName(f.Name); ok && ff.IsExported() {
+			pairs = append(pairs, [2]string{f.Name, f.Name})
+		}
+	}
+
+	return pairs, nil
+}
+
+// matchAll pairs every exported successor field against a predecessor
+// field of the same name and an assignable type, failing the static check
+// if any successor field is left unmapped.
+func matchAll(fromType, toType reflect.Type) ([][2]string, error) {
+	from, err := derefStructType(fromType)
+	if err != nil {
+		return nil, fmt.Errorf("MapAll requires a struct predecessor output: %w", err)
+	}
+	to, err := derefStructType(toType)
+	if err != nil {
+		return nil, fmt.Errorf("MapAll requires a struct successor input: %w", err)
+	}
+
+	var (
+		pairs    [][2]string
+		unmapped []string
+	)
+	for i := 0; i < to.NumField(); i++ {
+		f := to.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		ff, ok := from.FieldByName(f.Name)
+		if !ok || !ff.IsExported() || !ff.Type.AssignableTo(f.Type) {
+			unmapped = append(unmapped, f.Name)
+			continue
+		}
+
+		pairs = append(pairs, [2]string{f.Name, f.Name})
+	}
+
+	if len(unmapped) > 0 {
+		segs := make([]Segment, len(unmapped))
+		for i, name := range unmapped {
+			segs[i] = FieldSeg(name)
+		}
+		return nil, &FieldMappingError{
+			ToPath: segs,
+			Phase:  FieldMappingPhaseStatic,
+			Kind:   FieldMappingNotFound,
+			Err:    fmt.Errorf("unmapped successor fields: %s", strings.Join(unmapped, ", ")),
+		}
+	}
+
+	return pairs, nil
+}
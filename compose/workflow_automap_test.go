@@ -0,0 +1,198 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type amFrom struct {
+	A string `json:"a"`
+	B int    `json:"b"`
+	C string
+}
+
+type amTo struct {
+	A string `json:"a"`
+	B int    `json:"b"`
+	C string
+	D string
+}
+
+func TestMatchByTag(t *testing.T) {
+	pairs, err := matchByTag("json", reflect.TypeOf(amFrom{}), reflect.TypeOf(amTo{}))
+	if err != nil {
+		t.Fatalf("matchByTag: %v", err)
+	}
+	if len(pairs) != 2 {
+		t.Fatalf("expected 2 pairs, got %d: %v", len(pairs), pairs)
+	}
+}
+
+type dupTagFrom struct {
+	A string `json:"x"`
+	B string `json:"x"`
+}
+
+type dupTagTo struct {
+	A string `json:"x"`
+}
+
+func TestMatchByTagAmbiguousCollision(t *testing.T) {
+	_, err := matchByTag("json", reflect.TypeOf(dupTagFrom{}), reflect.TypeOf(dupTagTo{}))
+	if err == nil {
+		t.Fatal("expected an ambiguous-tag error")
+	}
+	var fme *FieldMappingError
+	if !errors.As(err, &fme) {
+		t.Fatalf("expected a *FieldMappingError, got %v", err)
+	}
+	if fme.Kind != FieldMappingAmbiguous {
+		t.Errorf("Kind = %v, want %v", fme.Kind, FieldMappingAmbiguous)
+	}
+}
+
+func TestMatchByName(t *testing.T) {
+	pairs, err := matchByName(reflect.TypeOf(amFrom{}), reflect.TypeOf(amTo{}))
+	if err != nil {
+		t.Fatalf("matchByName: %v", err)
+	}
+	if len(pairs) != 3 { // A, B, C all shared by name
+		t.Fatalf("expected 3 pairs, got %d: %v", len(pairs), pairs)
+	}
+}
+
+func TestMatchAllFailsOnUnmappedField(t *testing.T) {
+	_, err := matchAll(reflect.TypeOf(amFrom{}), reflect.TypeOf(amTo{}))
+	if err == nil {
+		t.Fatal("expected an error since amTo.D has no predecessor counterpart")
+	}
+	var fme *FieldMappingError
+	if !errors.As(err, &fme) {
+		t.Fatalf("expected a *FieldMappingError, got %v", err)
+	}
+	if fme.Kind != FieldMappingNotFound {
+		t.Errorf("Kind = %v, want %v", fme.Kind, FieldMappingNotFound)
+	}
+}
+
+type amAllFrom struct {
+	A string
+	B int
+}
+
+type amAllTo struct {
+	A string
+	B int
+}
+
+func TestMatchAllSucceedsWhenEveryFieldMaps(t *testing.T) {
+	pairs, err := matchAll(reflect.TypeOf(amAllFrom{}), reflect.TypeOf(amAllTo{}))
+	if err != nil {
+		t.Fatalf("matchAll: %v", err)
+	}
+	if len(pairs) != 2 {
+		t.Fatalf("expected 2 pairs, got %d: %v", len(pairs), pairs)
+	}
+}
+
+func TestDerefStructTypeRejectsNonStruct(t *testing.T) {
+	_, err := derefStructType(reflect.TypeOf(42))
+	if err == nil {
+		t.Fatal("expected an error for a non-struct type")
+	}
+	var fme *FieldMappingError
+	if !errors.As(err, &fme) {
+		t.Fatalf("expected a *FieldMappingError, got %v", err)
+	}
+	if fme.Kind != FieldMappingNotStructOrMap {
+		t.Errorf("Kind = %v, want %v", fme.Kind, FieldMappingNotStructOrMap)
+	}
+}
+
+func TestDerefStructTypeDereferencesPointer(t *testing.T) {
+	got, err := derefStructType(reflect.TypeOf(&amFrom{}))
+	if err != nil {
+		t.Fatalf("derefStructType: %v", err)
+	}
+	if got.Kind() != reflect.Struct {
+		t.Errorf("got kind %v, want struct", got.Kind())
+	}
+}
+
+func TestExpandAutoMappingsAggregatesEveryFailure(t *testing.T) {
+	wf := &Workflow[amFrom, amTo]{
+		nodes: map[string]*WorkflowNode{},
+	}
+
+	bad1 := NewMapping(START).MapAll() // amTo.D is unmapped -> fails
+	bad2 := NewMapping("missing").MapByName()
+
+	_, err := wf.expandAutoMappings([]*Mapping{bad1, bad2}, reflect.TypeOf(amTo{}))
+	if err == nil {
+		t.Fatal("expected an aggregated error")
+	}
+
+	var multi *MultiFieldMappingError
+	if !errors.As(err, &multi) {
+		t.Fatalf("expected a *MultiFieldMappingError, got %v", err)
+	}
+	if len(multi.Errs) != 2 {
+		t.Fatalf("expected both auto mappings to fail and be aggregated, got %d errs: %v", len(multi.Errs), multi.Errs)
+	}
+}
+
+func TestExpandAutoMappingsExplicitMappingWinsOverAuto(t *testing.T) {
+	wf := &Workflow[amAllFrom, amAllTo]{
+		nodes: map[string]*WorkflowNode{},
+	}
+
+	explicit := NewMapping(START).From("A").To("A")
+	auto := NewMapping(START).MapByName()
+
+	expanded, err := wf.expandAutoMappings([]*Mapping{explicit, auto}, reflect.TypeOf(amAllTo{}))
+	if err != nil {
+		t.Fatalf("expandAutoMappings: %v", err)
+	}
+
+	count := 0
+	for _, m := range expanded {
+		if m.to == "A" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("expected field A to be mapped exactly once (explicit wins), got %d: %v", count, expanded)
+	}
+}
+
+func TestExpandAutoMappingsNoAutoPassesThrough(t *testing.T) {
+	wf := &Workflow[amAllFrom, amAllTo]{
+		nodes: map[string]*WorkflowNode{},
+	}
+
+	plain := []*Mapping{NewMapping(START).From("A").To("A")}
+	got, err := wf.expandAutoMappings(plain, reflect.TypeOf(amAllTo{}))
+	if err != nil {
+		t.Fatalf("expandAutoMappings: %v", err)
+	}
+	if len(got) != 1 || got[0] != plain[0] {
+		t.Errorf("expected plain mappings to pass through unchanged, got %v", got)
+	}
+}
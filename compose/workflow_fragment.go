@@ -0,0 +1,164 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"fmt"
+
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/indexer"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/components/retriever"
+)
+
+// WorkflowFragment is a reusable, partial set of workflow nodes, carrying no
+// input/output type constraint of its own. It exists purely for composition
+// via Workflow.Include: build a fragment once (e.g. a retrieval sub-flow
+// shared by several agents), then import it, under a namespace prefix, into
+// as many concrete Workflow[I, O] as needed. A WorkflowFragment is never
+// compiled directly.
+type WorkflowFragment struct {
+	nodes map[string]*WorkflowNode
+	err   error
+}
+
+// NewWorkflowFragment creates a new, empty WorkflowFragment.
+func NewWorkflowFragment() *WorkflowFragment {
+	return &WorkflowFragment{nodes: make(map[string]*WorkflowNode)}
+}
+
+func (fr *WorkflowFragment) AddChatModelNode(key string, chatModel model.ChatModel, opts ...GraphAddNodeOpt) *WorkflowNode {
+	node := &WorkflowNode{key: key, typ: "chatmodel", component: chatModel, addOpts: opts}
+	fr.nodes[key] = node
+	return node
+}
+
+func (fr *WorkflowFragment) AddChatTemplateNode(key string, chatTemplate prompt.ChatTemplate, opts ...GraphAddNodeOpt) *WorkflowNode {
+	node := &WorkflowNode{key: key, typ: "template", component: chatTemplate, addOpts: opts}
+	fr.nodes[key] = node
+	return node
+}
+
+func (fr *WorkflowFragment) AddToolsNode(key string, tools *ToolsNode, opts ...GraphAddNodeOpt) *WorkflowNode {
+	node := &WorkflowNode{key: key, typ: "tools", component: tools, addOpts: opts}
+	fr.nodes[key] = node
+	return node
+}
+
+func (fr *WorkflowFragment) AddRetrieverNode(key string, retriever retriever.Retriever, opts ...GraphAddNodeOpt) *WorkflowNode {
+	node := &WorkflowNode{key: key, typ: "retriever", component: retriever, addOpts: opts}
+	fr.nodes[key] = node
+	return node
+}
+
+func (fr *WorkflowFragment) AddEmbeddingNode(key string, embedding embedding.Embedder, opts ...GraphAddNodeOpt) *WorkflowNode {
+	node := &WorkflowNode{key: key, typ: "embedding", component: embedding, addOpts: opts}
+	fr.nodes[key] = node
+	return node
+}
+
+func (fr *WorkflowFragment) AddIndexerNode(key string, indexer indexer.Indexer, opts ...GraphAddNodeOpt) *WorkflowNode {
+	node := &WorkflowNode{key: key, typ: "indexer", component: indexer, addOpts: opts}
+	fr.nodes[key] = node
+	return node
+}
+
+func (fr *WorkflowFragment) AddLoaderNode(key string, loader document.Loader, opts ...GraphAddNodeOpt) *WorkflowNode {
+	node := &WorkflowNode{key: key, typ: "loader", component: loader, addOpts: opts}
+	fr.nodes[key] = node
+	return node
+}
+
+func (fr *WorkflowFragment) AddDocumentTransformerNode(key string, transformer document.Transformer, opts ...GraphAddNodeOpt) *WorkflowNode {
+	node := &WorkflowNode{key: key, typ: "transformer", component: transformer, addOpts: opts}
+	fr.nodes[key] = node
+	return node
+}
+
+func (fr *WorkflowFragment) AddGraphNode(key string, graph AnyGraph, opts ...GraphAddNodeOpt) *WorkflowNode {
+	node := &WorkflowNode{key: key, typ: "graph", component: graph, addOpts: opts}
+	fr.nodes[key] = node
+	return node
+}
+
+func (fr *WorkflowFragment) AddLambdaNode(key string, lambda *Lambda, opts ...GraphAddNodeOpt) *WorkflowNode {
+	node := &WorkflowNode{key: key, typ: "lambda", component: lambda, addOpts: opts}
+	fr.nodes[key] = node
+	return node
+}
+
+// Include imports fragment's nodes into wf under the namespace prefix, so
+// "node" in the fragment becomes "prefix.node" in wf (an empty prefix keeps
+// keys as-is). Any Mapping whose fromNodeKey names another node of the same
+// fragment is rewritten to the prefixed key; a fromNodeKey outside the
+// fragment (e.g. START, or a key wf itself already defines) is left
+// untouched, letting a fragment's entry node be wired to the rest of wf
+// after inclusion.
+func (wf *Workflow[I, O]) Include(fragment *WorkflowFragment, prefix string) *Workflow[I, O] {
+	if wf.err != nil {
+		return wf
+	}
+	if fragment.err != nil {
+		wf.err = fmt.Errorf("cannot include a workflow fragment that failed to build: %w", fragment.err)
+		return wf
+	}
+
+	keyMap := make(map[string]string, len(fragment.nodes))
+	for key := range fragment.nodes {
+		keyMap[key] = prefixedNodeKey(prefix, key)
+	}
+
+	for key, node := range fragment.nodes {
+		rewritten := &WorkflowNode{
+			key:       keyMap[key],
+			typ:       node.typ,
+			component: node.component,
+			addOpts:   node.addOpts,
+			inputs:    rewriteFromNodeKeys(node.inputs, keyMap),
+		}
+		if err := wf.addNodeLike(rewritten.key, rewritten); err != nil {
+			return wf
+		}
+	}
+
+	return wf
+}
+
+func prefixedNodeKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// rewriteFromNodeKeys returns a copy of inputs with every fromNodeKey that
+// names a fragment-local node key replaced by its namespaced counterpart.
+func rewriteFromNodeKeys(inputs []*Mapping, keyMap map[string]string) []*Mapping {
+	rewritten := make([]*Mapping, len(inputs))
+	for i, m := range inputs {
+		// Copy the whole Mapping rather than hand-listing fields, so a
+		// future mapping kind doesn't need to remember to add itself here.
+		copied := *m
+		if mapped, ok := keyMap[copied.fromNodeKey]; ok {
+			copied.fromNodeKey = mapped
+		}
+		rewritten[i] = &copied
+	}
+	return rewritten
+}
@@ -0,0 +1,287 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"fmt"
+
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/indexer"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/components/retriever"
+)
+
+// MergeOption configures how Workflow.Merge reconciles a node key that
+// exists in both workflows.
+type MergeOption func(*mergeOptions)
+
+type mergeOptions struct {
+	overrideAll        bool
+	overrideKeys       map[string]bool
+	replaceInputsKeys  map[string]bool
+	replaceMappingKeys map[string]map[string]bool
+}
+
+func newMergeOptions(opts ...MergeOption) *mergeOptions {
+	o := &mergeOptions{
+		overrideKeys:       make(map[string]bool),
+		replaceInputsKeys:  make(map[string]bool),
+		replaceMappingKeys: make(map[string]map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func (o *mergeOptions) canOverride(key string) bool {
+	return o.overrideAll || o.overrideKeys[key]
+}
+
+func (o *mergeOptions) replaceInputs(key string) bool {
+	return o.replaceInputsKeys[key]
+}
+
+func (o *mergeOptions) canReplaceMapping(nodeKey, toField string) bool {
+	return o.replaceMappingKeys[nodeKey][toField]
+}
+
+// WithOverride allows the merged-in workflow's node(s) to replace a node
+// already present in the destination under the same key, instead of Merge
+// failing on the collision. With no keys given, every colliding key may be
+// overridden.
+func WithOverride(keys ...string) MergeOption {
+	return func(o *mergeOptions) {
+		if len(keys) == 0 {
+			o.overrideAll = true
+			return
+		}
+		for _, k := range keys {
+			o.overrideKeys[k] = true
+		}
+	}
+}
+
+// WithReplaceInputs makes the node identified by key take the merged-in
+// workflow's inputs wholesale, instead of the default of unioning both
+// sides' input Mappings.
+func WithReplaceInputs(key string) MergeOption {
+	return func(o *mergeOptions) {
+		o.replaceInputsKeys[key] = true
+	}
+}
+
+// WithReplaceMapping resolves a union conflict on node key's input field
+// toField, produced when both workflows map something to it, by
+// letting the merged-in workflow's Mapping win.
+func WithReplaceMapping(nodeKey, toField string) MergeOption {
+	return func(o *mergeOptions) {
+		if o.replaceMappingKeys[nodeKey] == nil {
+			o.replaceMappingKeys[nodeKey] = make(map[string]bool)
+		}
+		o.replaceMappingKeys[nodeKey][toField] = true
+	}
+}
+
+// Merge folds other's nodes and END mapping into wf, so multiple Workflow
+// definitions (e.g. a base workflow and a team-specific extension) can be
+// composed without redeclaring shared nodes. Nodes whose key only exists in
+// one of the two workflows are simply added. A colliding key is rejected
+// unless allowed via WithOverride, in which case other's node replaces wf's
+// under that key, and the input Mappings are unioned unless
+// WithReplaceInputs(key) is given. A union that finds both sides mapping to
+// the same input field fails unless resolved with WithReplaceMapping.
+func (wf *Workflow[I, O]) Merge(other *Workflow[I, O], opts ...MergeOption) *Workflow[I, O] {
+	if wf.err != nil {
+		return wf
+	}
+	if other.err != nil {
+		wf.err = fmt.Errorf("cannot merge a workflow that failed to build: %w", other.err)
+		return wf
+	}
+
+	options := newMergeOptions(opts...)
+
+	var needsRebuild bool
+
+	for key, srcNode := range other.nodes {
+		dstNode, exists := wf.nodes[key]
+		if !exists {
+			if wf.addNodeLike(key, srcNode) != nil {
+				return wf
+			}
+			continue
+		}
+
+		if !options.canOverride(key) {
+			wf.err = fmt.Errorf("workflow merge: node = %s already exists, use WithOverride(%q) to replace it", key, key)
+			return wf
+		}
+
+		mergedNode := &WorkflowNode{
+			key:        key,
+			typ:        srcNode.typ,
+			component:  srcNode.component,
+			addOpts:    srcNode.addOpts,
+			inputType:  srcNode.inputType,
+			outputType: srcNode.outputType,
+		}
+
+		if options.replaceInputs(key) {
+			mergedNode.inputs = srcNode.inputs
+		} else {
+			merged, err := unionInputs(key, dstNode.inputs, srcNode.inputs, options)
+			if err != nil {
+				wf.err = err
+				return wf
+			}
+			mergedNode.inputs = merged
+		}
+
+		// the colliding key's node already exists in wf.gg under its old
+		// component; Graph has no way to remove or replace it in place, so
+		// record the winning node and rebuild gg from wf.nodes once the
+		// whole merge is known to have overridden something.
+		wf.nodes[key] = mergedNode
+		needsRebuild = true
+	}
+
+	if needsRebuild {
+		if err := wf.rebuildGraph(); err != nil {
+			return wf
+		}
+	}
+
+	switch {
+	case len(other.end) == 0:
+	case len(wf.end) == 0:
+		wf.end = other.end
+	default:
+		wf.end = append(append([]*Mapping{}, wf.end...), other.end...)
+	}
+
+	return wf
+}
+
+// unionInputs combines dst and src's input Mappings for nodeKey, keeping
+// dst's relative order and appending anything new from src. A Mapping in
+// both sides that targets the same "to" field is a conflict, resolved only
+// if the caller allowed it with WithReplaceMapping, in which case src wins.
+func unionInputs(nodeKey string, dst, src []*Mapping, o *mergeOptions) ([]*Mapping, error) {
+	byTo := make(map[string]*Mapping, len(dst)+len(src))
+	order := make([]string, 0, len(dst)+len(src))
+
+	for _, m := range dst {
+		byTo[m.to] = m
+		order = append(order, m.to)
+	}
+
+	for _, m := range src {
+		if _, ok := byTo[m.to]; ok {
+			if !o.canReplaceMapping(nodeKey, m.to) {
+				return nil, fmt.Errorf("workflow merge: node = %s has colliding mappings to field = %q, use WithReplaceMapping(%q, %q) to pick one", nodeKey, m.to, nodeKey, m.to)
+			}
+		} else {
+			order = append(order, m.to)
+		}
+		byTo[m.to] = m
+	}
+
+	merged := make([]*Mapping, 0, len(order))
+	for _, to := range order {
+		merged = append(merged, byTo[to])
+	}
+	return merged, nil
+}
+
+// addComponentNode registers src's component onto gg under key using the
+// low-level *Graph API directly, keyed off the recorded typ the same way
+// Workflow's own AddXxxNode methods dispatch by static type. It never
+// touches a Workflow's nodes map, so it can be replayed freely: once live,
+// against wf.gg (addNodeLike), and again, against a freshly built Graph, by
+// rebuildGraph.
+func addComponentNode[I, O any](gg *Graph[I, O], key string, src *WorkflowNode) error {
+	switch src.typ {
+	case "chatmodel":
+		return gg.AddChatModelNode(key, src.component.(model.ChatModel), src.addOpts...)
+	case "template":
+		return gg.AddChatTemplateNode(key, src.component.(prompt.ChatTemplate), src.addOpts...)
+	case "tools":
+		return gg.AddToolsNode(key, src.component.(*ToolsNode), src.addOpts...)
+	case "retriever":
+		return gg.AddRetrieverNode(key, src.component.(retriever.Retriever), src.addOpts...)
+	case "embedding":
+		return gg.AddEmbeddingNode(key, src.component.(embedding.Embedder), src.addOpts...)
+	case "indexer":
+		return gg.AddIndexerNode(key, src.component.(indexer.Indexer), src.addOpts...)
+	case "loader":
+		return gg.AddLoaderNode(key, src.component.(document.Loader), src.addOpts...)
+	case "transformer":
+		return gg.AddDocumentTransformerNode(key, src.component.(document.Transformer), src.addOpts...)
+	case "graph":
+		return gg.AddGraphNode(key, src.component.(AnyGraph), src.addOpts...)
+	case "lambda":
+		return gg.AddLambdaNode(key, src.component.(*Lambda), src.addOpts...)
+	default:
+		return fmt.Errorf("workflow: node = %s has unknown type = %q", key, src.typ)
+	}
+}
+
+// addNodeLike replays the AddXxxNode call recorded on src against wf under
+// key, the shared mechanism behind both Merge (new key, no collision) and
+// Include (fragment nodes, imported fresh).
+func (wf *Workflow[I, O]) addNodeLike(key string, src *WorkflowNode) error {
+	node := &WorkflowNode{
+		key:        key,
+		typ:        src.typ,
+		component:  src.component,
+		addOpts:    src.addOpts,
+		inputType:  src.inputType,
+		outputType: src.outputType,
+	}
+
+	if err := addComponentNode(wf.gg, key, node); err != nil {
+		wf.err = err
+		return err
+	}
+
+	node.inputs = append(node.inputs, src.inputs...)
+	wf.nodes[key] = node
+	return nil
+}
+
+// rebuildGraph recreates wf.gg from scratch and replays every node
+// currently recorded in wf.nodes. Graph has no primitive to remove or
+// replace an already-added node, so this is the only way Merge's
+// WithOverride can swap out a node's underlying component once it has
+// already been added once under the same key.
+func (wf *Workflow[I, O]) rebuildGraph() error {
+	gg := NewGraph[I, O](wf.newGraphOpts...)
+	gg.cmp = ComponentOfWorkflow
+
+	for key, node := range wf.nodes {
+		if err := addComponentNode(gg, key, node); err != nil {
+			wf.err = fmt.Errorf("workflow merge: rebuilding graph after override failed: %w", err)
+			return wf.err
+		}
+	}
+
+	wf.gg = gg
+	return nil
+}
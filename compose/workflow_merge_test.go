@@ -0,0 +1,159 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"context"
+	"testing"
+)
+
+func taggedLambda(tag string) *Lambda {
+	return InvokableLambda(func(_ context.Context, in string) (string, error) {
+		return tag + ":" + in, nil
+	})
+}
+
+func TestMergeAddsNonCollidingNode(t *testing.T) {
+	wf := NewWorkflow[string, string]()
+	wf.AddLambdaNode("n1", taggedLambda("n1")).AddInput(NewMapping(START))
+
+	other := NewWorkflow[string, string]()
+	other.AddLambdaNode("n2", taggedLambda("n2")).AddInput(NewMapping(START))
+
+	wf.Merge(other)
+	if wf.err != nil {
+		t.Fatalf("Merge failed: %v", wf.err)
+	}
+	if _, ok := wf.nodes["n2"]; !ok {
+		t.Fatal("expected other's node n2 to be added to wf")
+	}
+}
+
+func TestMergeCollisionWithoutOverrideFails(t *testing.T) {
+	wf := NewWorkflow[string, string]()
+	wf.AddLambdaNode("n1", taggedLambda("base")).AddInput(NewMapping(START))
+
+	other := NewWorkflow[string, string]()
+	other.AddLambdaNode("n1", taggedLambda("override")).AddInput(NewMapping(START))
+
+	wf.Merge(other)
+	if wf.err == nil {
+		t.Fatal("expected Merge to fail on a colliding node key without WithOverride")
+	}
+}
+
+// TestMergeWithOverrideReplacesComponent is the regression test for the
+// WithOverride bug: replaying AddLambdaNode against wf.gg under an
+// already-present key used to fail with a duplicate-node error, silently
+// stashed into wf.err. Merge now rebuilds wf.gg from wf.nodes whenever an
+// override actually happens, so the swap succeeds.
+func TestMergeWithOverrideReplacesComponent(t *testing.T) {
+	wf := NewWorkflow[string, string]()
+	wf.AddLambdaNode("n1", taggedLambda("base")).AddInput(NewMapping(START))
+
+	other := NewWorkflow[string, string]()
+	other.AddLambdaNode("n1", taggedLambda("override")).AddInput(NewMapping(START))
+
+	// both n1 nodes map the whole START output, so their inputs would
+	// collide on the same field; take other's inputs wholesale.
+	wf.Merge(other, WithOverride("n1"), WithReplaceInputs("n1"))
+	if wf.err != nil {
+		t.Fatalf("Merge with WithOverride failed: %v", wf.err)
+	}
+
+	node, ok := wf.nodes["n1"]
+	if !ok {
+		t.Fatal("expected node n1 to still be present after override")
+	}
+	if node.component != other.nodes["n1"].component {
+		t.Error("expected n1's component to be replaced by other's")
+	}
+}
+
+func TestMergeUnionsInputsAndDetectsMappingConflict(t *testing.T) {
+	wf := NewWorkflow[string, string]()
+	wf.AddLambdaNode("n1", taggedLambda("base")).AddInput(NewMapping(START).To("A"))
+
+	other := NewWorkflow[string, string]()
+	other.AddLambdaNode("n1", taggedLambda("override")).AddInput(NewMapping(START).To("A"))
+
+	wf.Merge(other, WithOverride("n1"))
+	if wf.err == nil {
+		t.Fatal("expected a union conflict when both sides map to the same field")
+	}
+}
+
+func TestMergeWithReplaceMappingResolvesConflict(t *testing.T) {
+	wf := NewWorkflow[string, string]()
+	wf.AddLambdaNode("n1", taggedLambda("base")).AddInput(NewMapping(START).To("A"))
+
+	other := NewWorkflow[string, string]()
+	other.AddLambdaNode("n1", taggedLambda("override")).AddInput(NewMapping(START).To("A"))
+
+	wf.Merge(other, WithOverride("n1"), WithReplaceMapping("n1", "A"))
+	if wf.err != nil {
+		t.Fatalf("Merge with WithReplaceMapping failed: %v", wf.err)
+	}
+}
+
+func TestIncludeRewritesFragmentLocalFromNode(t *testing.T) {
+	fr := NewWorkflowFragment()
+	fr.AddLambdaNode("src", taggedLambda("src")).AddInput(NewMapping(START))
+	fr.AddLambdaNode("dst", taggedLambda("dst")).AddInput(NewMapping("src"))
+
+	wf := NewWorkflow[string, string]()
+	wf.Include(fr, "frag")
+	if wf.err != nil {
+		t.Fatalf("Include failed: %v", wf.err)
+	}
+
+	dst, ok := wf.nodes["frag.dst"]
+	if !ok {
+		t.Fatal("expected fragment node dst to be prefixed to frag.dst")
+	}
+	if len(dst.inputs) != 1 || dst.inputs[0].fromNodeKey != "frag.src" {
+		t.Errorf("expected dst's from_node to be rewritten to frag.src, got %+v", dst.inputs)
+	}
+}
+
+// TestIncludePreservesSelectModeMapping is the regression test for
+// rewriteFromNodeKeys dropping selectFrom/selectTo/autoExcept when copying a
+// fragment's Mappings: a fragment node built with Select/FromFields/Except
+// must keep its field lists after Include, not just its autoMode.
+func TestIncludePreservesSelectModeMapping(t *testing.T) {
+	fr := NewWorkflowFragment()
+	fr.AddLambdaNode("src", taggedLambda("src")).AddInput(NewMapping(START))
+	fr.AddLambdaNode("dst", taggedLambda("dst")).AddInput(NewMapping("src").Select([2]string{"A", "B"}))
+
+	wf := NewWorkflow[string, string]()
+	wf.Include(fr, "frag")
+	if wf.err != nil {
+		t.Fatalf("Include failed: %v", wf.err)
+	}
+
+	dst, ok := wf.nodes["frag.dst"]
+	if !ok {
+		t.Fatal("expected fragment node dst to be prefixed to frag.dst")
+	}
+	if len(dst.inputs) != 1 {
+		t.Fatalf("expected one input, got %d", len(dst.inputs))
+	}
+	m := dst.inputs[0]
+	if len(m.selectFrom) != 1 || m.selectFrom[0] != "A" || len(m.selectTo) != 1 || m.selectTo[0] != "B" {
+		t.Errorf("expected Select's selectFrom/selectTo to survive Include, got selectFrom=%v selectTo=%v", m.selectFrom, m.selectTo)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compose
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSelectPairsValidatesBothSides(t *testing.T) {
+	m := &Mapping{fromNodeKey: "n1"}
+	m.Select([2]string{"A", "A"}, [2]string{"B", "B"})
+
+	pairs, err := m.selectPairs(reflect.TypeOf(amFrom{}), reflect.TypeOf(amTo{}))
+	if err != nil {
+		t.Fatalf("selectPairs: %v", err)
+	}
+	if len(pairs) != 2 {
+		t.Fatalf("expected 2 pairs, got %d: %v", len(pairs), pairs)
+	}
+}
+
+func TestSelectPairsMissingFieldReturnsFieldMappingError(t *testing.T) {
+	m := &Mapping{fromNodeKey: "n1"}
+	m.FromFields("NoSuchField")
+
+	_, err := m.selectPairs(reflect.TypeOf(amFrom{}), reflect.TypeOf(amTo{}))
+	if err == nil {
+		t.Fatal("expected an error for a missing field")
+	}
+	var fme *FieldMappingError
+	if !errors.As(err, &fme) {
+		t.Fatalf("expected a *FieldMappingError, got %v", err)
+	}
+	if fme.Kind != FieldMappingNotFound {
+		t.Errorf("Kind = %v, want %v", fme.Kind, FieldMappingNotFound)
+	}
+}
+
+func TestSelectPairsToFieldsOnlyMirrorsFromNames(t *testing.T) {
+	m := &Mapping{fromNodeKey: "n1"}
+	m.ToFields("A", "B")
+
+	pairs, err := m.selectPairs(reflect.TypeOf(amFrom{}), reflect.TypeOf(amTo{}))
+	if err != nil {
+		t.Fatalf("selectPairs: %v", err)
+	}
+	if len(pairs) != 2 || pairs[0][0] != "A" || pairs[0][1] != "A" {
+		t.Errorf("expected ToFields with no FromFields to pair by the same name, got %v", pairs)
+	}
+}
+
+func TestSelectPairsMismatchedLengthsFail(t *testing.T) {
+	m := &Mapping{fromNodeKey: "n1"}
+	m.FromFields("A", "B")
+	m.ToFields("A")
+
+	_, err := m.selectPairs(reflect.TypeOf(amFrom{}), reflect.TypeOf(amTo{}))
+	if err == nil {
+		t.Fatal("expected an error when FromFields and ToFields have different lengths")
+	}
+}
+
+func TestMatchExceptSkipsNamedFields(t *testing.T) {
+	pairs, err := matchExcept([]string{"B"}, reflect.TypeOf(amFrom{}), reflect.TypeOf(amTo{}))
+	if err != nil {
+		t.Fatalf("matchExcept: %v", err)
+	}
+	for _, p := range pairs {
+		if p[1] == "B" {
+			t.Fatalf("expected field B to be excluded, got pairs %v", pairs)
+		}
+	}
+	if len(pairs) != 2 { // A and C, not B (excluded) nor D (unmapped, left alone)
+		t.Fatalf("expected 2 pairs, got %d: %v", len(pairs), pairs)
+	}
+}
+
+func TestMatchExceptLeavesUnassignableFieldsUnmapped(t *testing.T) {
+	pairs, err := matchExcept(nil, reflect.TypeOf(amFrom{}), reflect.TypeOf(amTo{}))
+	if err != nil {
+		t.Fatalf("matchExcept: %v", err)
+	}
+	for _, p := range pairs {
+		if p[1] == "D" {
+			t.Fatalf("expected unmapped field D to be left out rather than failing, got %v", pairs)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflowfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+// resolvedNode is a nodeSpec after include-time key prefixing, still carrying
+// its originating file so extends/error reporting stays accurate, and the
+// node namespace it was flattened into, so a same-file "extends: anotherKey"
+// reference resolves against the namespace it actually belongs to instead of
+// whichever namespace happens to be in scope at the call site.
+type resolvedNode struct {
+	file   string
+	key    string
+	prefix string
+	spec   nodeSpec
+	ns     map[string]*resolvedNode
+}
+
+// builder flattens a root workflow file (and everything it includes) into a
+// single node namespace, resolves extends references, and wires the result
+// into a *compose.Workflow via the caller's component registry.
+type builder struct {
+	registry map[string]any
+	cache    map[string]*fileSpec
+
+	// extNS caches the namespace a "file.yaml#nodeKey" extends reference
+	// resolves against, keyed by the external file's absolute path, so a
+	// file extended from more than once isn't reflattened each time.
+	extNS map[string]map[string]*resolvedNode
+}
+
+func newBuilder(registry map[string]any) (*builder, error) {
+	if registry == nil {
+		return nil, fmt.Errorf("workflowfile: registry must not be nil")
+	}
+	return &builder{registry: registry, cache: make(map[string]*fileSpec), extNS: make(map[string]map[string]*resolvedNode)}, nil
+}
+
+func (b *builder) parse(path string) (*fileSpec, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("workflowfile: resolve path %s: %w", path, err)
+	}
+
+	if spec, ok := b.cache[abs]; ok {
+		return spec, nil
+	}
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, fmt.Errorf("workflowfile: read %s: %w", path, err)
+	}
+
+	spec, err := parseFile(data)
+	if err != nil {
+		return nil, newLoadErr(path, 0, "", fmt.Errorf("parse yaml: %w", err))
+	}
+
+	b.cache[abs] = spec
+	return spec, nil
+}
+
+// flatten parses path, recursively merges its include:'d files under their
+// own prefixes, and adds path's own nodes under prefix into out.
+func (b *builder) flatten(path, prefix string, out map[string]*resolvedNode) error {
+	spec, err := b.parse(path)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+
+	for _, inc := range spec.Include {
+		incPath := inc.Path
+		if !filepath.IsAbs(incPath) {
+			incPath = filepath.Join(dir, incPath)
+		}
+		if err = b.flatten(incPath, prefix+inc.Prefix, out); err != nil {
+			return err
+		}
+	}
+
+	for key, n := range spec.Nodes {
+		fullKey := prefix + key
+		if _, exists := out[fullKey]; exists {
+			return newLoadErr(path, n.line, key, fmt.Errorf("duplicate node key %q after applying prefix %q", fullKey, prefix))
+		}
+
+		rewritten := n
+		rewritten.Inputs = make([]mappingSpec, len(n.Inputs))
+		for i, m := range n.Inputs {
+			rewritten.Inputs[i] = rewriteFromNode(m, prefix, spec.Nodes)
+		}
+
+		out[fullKey] = &resolvedNode{file: path, key: fullKey, prefix: prefix, spec: rewritten, ns: out}
+	}
+
+	return nil
+}
+
+// flattenExternal flattens file in isolation, the same way flatten flattens
+// the root file and its include:'d files, but into its own namespace rather
+// than the importing workflow's. It backs the "file.yaml#nodeKey" form of
+// extends, so a reference to a sibling node, or a chained same-file extends,
+// found inside the external file resolves against that file's own node set
+// instead of the importing workflow's.
+func (b *builder) flattenExternal(file string) (map[string]*resolvedNode, error) {
+	if ns, ok := b.extNS[file]; ok {
+		return ns, nil
+	}
+
+	ns := make(map[string]*resolvedNode)
+	if err := b.flatten(file, "", ns); err != nil {
+		return nil, err
+	}
+
+	b.extNS[file] = ns
+	return ns, nil
+}
+
+// pullTransitive pulls into merged/resolved only the nodes that an inherited
+// input's from_node actually reaches, directly or transitively, starting
+// from whatever apply has resolved so far. A "file.yaml#nodeKey" extends
+// inherits that node's inputs wholesale, which may reference a sibling
+// defined in the same external file; that sibling (and, recursively,
+// whatever its own inputs reference) needs to exist in the final graph
+// under the key flattenExternal gave it, but the rest of that file's nodes
+// are never wired in just because they happen to share its namespace.
+func (b *builder) pullTransitive(merged map[string]*resolvedNode, resolved map[string]nodeSpec) error {
+	queue := make([]string, 0, len(resolved))
+	for key := range resolved {
+		queue = append(queue, key)
+	}
+
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+
+		for _, m := range resolved[key].Inputs {
+			ref := m.FromNode
+			if ref == "" {
+				continue
+			}
+			if _, exists := resolved[ref]; exists {
+				continue
+			}
+
+			n := b.findExternal(ref)
+			if n == nil {
+				// not something any cross-file extends has flattened; leave
+				// it for addNode/AddInput to report as an unknown from_node.
+				continue
+			}
+
+			spec, err := b.resolveExtends(n)
+			if err != nil {
+				return err
+			}
+			merged[ref] = n
+			resolved[ref] = spec
+			queue = append(queue, ref)
+		}
+	}
+
+	return nil
+}
+
+// findExternal looks up key across every file flattened so far via a
+// cross-file extends, so a transitively-referenced sibling resolves
+// regardless of which externally-extended file it actually lives in.
+func (b *builder) findExternal(key string) *resolvedNode {
+	for _, ns := range b.extNS {
+		if n, ok := ns[key]; ok {
+			return n
+		}
+	}
+	return nil
+}
+
+// rewriteFromNode prefixes a mapping's from_node when it names a sibling
+// node defined in the same file, leaving references to the workflow's start
+// input (an empty from_node) or to already-global keys untouched.
+func rewriteFromNode(m mappingSpec, prefix string, siblings map[string]nodeSpec) mappingSpec {
+	if m.FromNode == "" {
+		return m
+	}
+	if _, isSibling := siblings[m.FromNode]; isSibling {
+		m.FromNode = prefix + m.FromNode
+	}
+	return m
+}
+
+// resolveExtends returns n's effective nodeSpec after walking its (possibly
+// chained) extends reference and overlaying n's own explicit fields on top.
+func (b *builder) resolveExtends(n *resolvedNode) (nodeSpec, error) {
+	if n.spec.Extends == "" {
+		return n.spec, nil
+	}
+
+	base, err := b.lookupExtends(n)
+	if err != nil {
+		return nodeSpec{}, err
+	}
+
+	baseSpec, err := b.resolveExtends(base)
+	if err != nil {
+		return nodeSpec{}, err
+	}
+
+	out := baseSpec
+	out.Extends = ""
+	out.line = n.spec.line
+	if n.spec.Type != "" {
+		out.Type = n.spec.Type
+	}
+	if n.spec.Component != "" {
+		out.Component = n.spec.Component
+	}
+	if len(n.spec.Inputs) > 0 {
+		// an explicit input replaces any inherited one with the same to_field
+		overridden := make(map[string]bool, len(n.spec.Inputs))
+		for _, m := range n.spec.Inputs {
+			overridden[m.ToField] = true
+		}
+		merged := make([]mappingSpec, 0, len(baseSpec.Inputs)+len(n.spec.Inputs))
+		for _, m := range baseSpec.Inputs {
+			if !overridden[m.ToField] {
+				merged = append(merged, m)
+			}
+		}
+		merged = append(merged, n.spec.Inputs...)
+		out.Inputs = merged
+	}
+
+	return out, nil
+}
+
+// lookupExtends resolves an extends reference, either "nodeKey" (looked up
+// in n's own namespace, so a prefix picked up via include: still applies) or
+// "file.yaml#nodeKey" (resolved against that file's own namespace, built by
+// flattenExternal the same way the root file and its include:'d files are,
+// independent of whether that file was also include:'d here).
+func (b *builder) lookupExtends(n *resolvedNode) (*resolvedNode, error) {
+	ref := n.spec.Extends
+
+	if idx := strings.IndexByte(ref, '#'); idx >= 0 {
+		file, key := ref[:idx], ref[idx+1:]
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(n.file), file)
+		}
+
+		ns, err := b.flattenExternal(file)
+		if err != nil {
+			return nil, err
+		}
+
+		base, ok := ns[key]
+		if !ok {
+			return nil, newLoadErr(n.file, n.spec.line, n.key, fmt.Errorf("extends %q: node %q not found in %s", ref, key, file))
+		}
+
+		return base, nil
+	}
+
+	base, ok := n.ns[n.prefix+ref]
+	if !ok {
+		return nil, newLoadErr(n.file, n.spec.line, n.key, fmt.Errorf("extends %q: node not found", ref))
+	}
+
+	return base, nil
+}
+
+// apply wires every resolved node, and the root file's end mapping, into wf.
+// It is a free function rather than a builder method because Workflow's
+// node constructors are parameterized on the node's component type while
+// carrying the workflow's own I/O type parameters, which a non-generic
+// method on *builder cannot express.
+func apply[I, O any](b *builder, wf *compose.Workflow[I, O], rootPath string) error {
+	merged := make(map[string]*resolvedNode)
+	if err := b.flatten(rootPath, "", merged); err != nil {
+		return err
+	}
+
+	resolved := make(map[string]nodeSpec, len(merged))
+	for key, n := range merged {
+		spec, err := b.resolveExtends(n)
+		if err != nil {
+			return err
+		}
+		resolved[key] = spec
+	}
+
+	if err := b.pullTransitive(merged, resolved); err != nil {
+		return err
+	}
+
+	for key, spec := range resolved {
+		n := merged[key]
+		node, err := addNode(b, wf, key, spec)
+		if err != nil {
+			return newLoadErr(n.file, spec.line, key, err)
+		}
+
+		for _, m := range spec.Inputs {
+			node.AddInput(toMapping(m))
+		}
+	}
+
+	rootSpec, err := b.parse(rootPath)
+	if err != nil {
+		return err
+	}
+
+	if len(rootSpec.End) == 0 {
+		return newLoadErr(rootPath, 0, "", fmt.Errorf("workflow file has no end: mapping"))
+	}
+
+	ends := make([]*compose.Mapping, len(rootSpec.End))
+	for i, m := range rootSpec.End {
+		ends[i] = toMapping(m)
+	}
+	wf.AddEnd(ends...)
+
+	return nil
+}
+
+func toMapping(m mappingSpec) *compose.Mapping {
+	mapping := compose.NewMapping(fromNodeKeyOf(m))
+	if m.FromField != "" {
+		mapping = mapping.From(m.FromField)
+	}
+	if m.ToField != "" {
+		mapping = mapping.To(m.ToField)
+	}
+	return mapping
+}
+
+func fromNodeKeyOf(m mappingSpec) string {
+	if m.FromNode == "" {
+		return compose.START
+	}
+	return m.FromNode
+}
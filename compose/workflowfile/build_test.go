@@ -0,0 +1,353 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflowfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeYAML(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestFlattenPrefixesIncludedNodesAndRewritesSiblingFromNode(t *testing.T) {
+	dir := t.TempDir()
+	writeYAML(t, dir, "sub.yaml", `
+nodes:
+  src:
+    type: lambda
+    component: srcComp
+  dst:
+    type: lambda
+    component: dstComp
+    inputs:
+      - from_node: src
+        to_field: In
+end:
+  - from_node: dst
+`)
+	main := writeYAML(t, dir, "main.yaml", `
+include:
+  - path: sub.yaml
+    prefix: sub.
+nodes:
+  top:
+    type: lambda
+    component: topComp
+end:
+  - from_node: top
+`)
+
+	b, err := newBuilder(map[string]any{})
+	if err != nil {
+		t.Fatalf("newBuilder: %v", err)
+	}
+
+	out := make(map[string]*resolvedNode)
+	if err := b.flatten(main, "", out); err != nil {
+		t.Fatalf("flatten: %v", err)
+	}
+
+	if _, ok := out["sub.src"]; !ok {
+		t.Fatalf("expected included node to be prefixed to sub.src, got keys %v", keysOf(out))
+	}
+	dst, ok := out["sub.dst"]
+	if !ok {
+		t.Fatalf("expected included node to be prefixed to sub.dst, got keys %v", keysOf(out))
+	}
+	if got := dst.spec.Inputs[0].FromNode; got != "sub.src" {
+		t.Errorf("expected sibling from_node to be rewritten to sub.src, got %q", got)
+	}
+	if _, ok := out["top"]; !ok {
+		t.Fatalf("expected root file's own node top to keep its unprefixed key")
+	}
+}
+
+func TestResolveExtendsSameFile(t *testing.T) {
+	dir := t.TempDir()
+	main := writeYAML(t, dir, "main.yaml", `
+nodes:
+  base:
+    type: lambda
+    component: baseComp
+    inputs:
+      - from_field: Query
+        to_field: In
+  derived:
+    type: lambda
+    extends: base
+    inputs:
+      - from_field: Extra
+        to_field: Aux
+end:
+  - from_node: derived
+`)
+
+	b, err := newBuilder(map[string]any{})
+	if err != nil {
+		t.Fatalf("newBuilder: %v", err)
+	}
+
+	out := make(map[string]*resolvedNode)
+	if err := b.flatten(main, "", out); err != nil {
+		t.Fatalf("flatten: %v", err)
+	}
+
+	resolved, err := b.resolveExtends(out["derived"])
+	if err != nil {
+		t.Fatalf("resolveExtends: %v", err)
+	}
+	if resolved.Type != "lambda" || resolved.Component != "baseComp" {
+		t.Errorf("expected inherited type/component from base, got type=%q component=%q", resolved.Type, resolved.Component)
+	}
+	if len(resolved.Inputs) != 2 {
+		t.Fatalf("expected base and derived inputs to be merged, got %d inputs", len(resolved.Inputs))
+	}
+}
+
+func TestResolveExtendsCrossFileRewritesSiblingFromNode(t *testing.T) {
+	dir := t.TempDir()
+	writeYAML(t, dir, "base.yaml", `
+nodes:
+  src:
+    type: lambda
+    component: srcComp
+  tpl:
+    type: lambda
+    component: tplComp
+    inputs:
+      - from_node: src
+        to_field: In
+end:
+  - from_node: tpl
+`)
+	main := writeYAML(t, dir, "main.yaml", `
+include:
+  - path: base.yaml
+    prefix: base.
+nodes:
+  borrowed:
+    type: lambda
+    extends: base.yaml#tpl
+end:
+  - from_node: borrowed
+`)
+
+	b, err := newBuilder(map[string]any{})
+	if err != nil {
+		t.Fatalf("newBuilder: %v", err)
+	}
+
+	out := make(map[string]*resolvedNode)
+	if err := b.flatten(main, "", out); err != nil {
+		t.Fatalf("flatten: %v", err)
+	}
+
+	resolved, err := b.resolveExtends(out["borrowed"])
+	if err != nil {
+		t.Fatalf("resolveExtends: %v", err)
+	}
+	if len(resolved.Inputs) != 1 {
+		t.Fatalf("expected one inherited input, got %d", len(resolved.Inputs))
+	}
+	// base.yaml is flattened into its own isolated namespace independent of
+	// whatever prefix main.yaml happened to give it via include:, so the
+	// sibling reference is rewritten against that isolated namespace, not
+	// main.yaml's "base." include prefix.
+	if got := resolved.Inputs[0].FromNode; got != "src" {
+		t.Errorf("expected cross-file sibling from_node to be rewritten to src, got %q", got)
+	}
+}
+
+func TestLookupExtendsChainedCrossFileUsesExternalNamespace(t *testing.T) {
+	dir := t.TempDir()
+	writeYAML(t, dir, "base.yaml", `
+nodes:
+  root:
+    type: lambda
+    component: rootComp
+    inputs:
+      - from_field: Query
+        to_field: In
+  mid:
+    type: lambda
+    extends: root
+    inputs:
+      - from_field: Extra
+        to_field: Aux
+end:
+  - from_node: mid
+`)
+	// main.yaml deliberately does NOT include base.yaml, and deliberately
+	// declares its own unrelated node named "root", to prove that resolving
+	// base.yaml#mid's chained "extends: root" finds base.yaml's own root and
+	// not main.yaml's same-named one.
+	main := writeYAML(t, dir, "main.yaml", `
+nodes:
+  root:
+    type: lambda
+    component: mainRootComp
+  borrowed:
+    type: lambda
+    extends: base.yaml#mid
+end:
+  - from_node: borrowed
+`)
+
+	b, err := newBuilder(map[string]any{})
+	if err != nil {
+		t.Fatalf("newBuilder: %v", err)
+	}
+
+	out := make(map[string]*resolvedNode)
+	if err := b.flatten(main, "", out); err != nil {
+		t.Fatalf("flatten: %v", err)
+	}
+
+	resolved, err := b.resolveExtends(out["borrowed"])
+	if err != nil {
+		t.Fatalf("resolveExtends: %v", err)
+	}
+	if resolved.Component != "rootComp" {
+		t.Errorf("expected chained extends to resolve against base.yaml's own root (component=rootComp), got component=%q", resolved.Component)
+	}
+	if len(resolved.Inputs) != 1 || resolved.Inputs[0].ToField != "In" {
+		t.Errorf("expected root's own input to be inherited through the chain, got %+v", resolved.Inputs)
+	}
+}
+
+func TestFlattenExternalIsCachedPerFile(t *testing.T) {
+	dir := t.TempDir()
+	writeYAML(t, dir, "base.yaml", `
+nodes:
+  a:
+    type: lambda
+    component: aComp
+end:
+  - from_node: a
+`)
+
+	b, err := newBuilder(map[string]any{})
+	if err != nil {
+		t.Fatalf("newBuilder: %v", err)
+	}
+
+	abs, err := filepath.Abs(filepath.Join(dir, "base.yaml"))
+	if err != nil {
+		t.Fatalf("abs: %v", err)
+	}
+
+	ns1, err := b.flattenExternal(abs)
+	if err != nil {
+		t.Fatalf("flattenExternal: %v", err)
+	}
+	ns2, err := b.flattenExternal(abs)
+	if err != nil {
+		t.Fatalf("flattenExternal: %v", err)
+	}
+	if len(ns1) != 1 {
+		t.Fatalf("expected one flattened node, got %d", len(ns1))
+	}
+	if a1, a2 := ns1["a"], ns2["a"]; a1 != a2 {
+		t.Errorf("expected flattenExternal to cache and return the same *resolvedNode for repeat calls")
+	}
+}
+
+// TestPullTransitiveOnlyAddsReferencedExternalNodes is the regression test
+// for apply() over-pulling: extending a single node from an external file
+// must bring in only that node and whatever its inherited inputs actually
+// reference, transitively, not every unrelated node flatten() happened to
+// put in that file's namespace.
+func TestPullTransitiveOnlyAddsReferencedExternalNodes(t *testing.T) {
+	dir := t.TempDir()
+	writeYAML(t, dir, "base.yaml", `
+nodes:
+  root:
+    type: lambda
+    component: rootComp
+  mid:
+    type: lambda
+    extends: root
+    inputs:
+      - from_node: root
+        to_field: In
+  other1:
+    type: lambda
+    component: otherComp
+  other2:
+    type: lambda
+    component: otherComp
+end:
+  - from_node: mid
+`)
+	main := writeYAML(t, dir, "main.yaml", `
+nodes:
+  borrowed:
+    type: lambda
+    extends: base.yaml#mid
+end:
+  - from_node: borrowed
+`)
+
+	b, err := newBuilder(map[string]any{})
+	if err != nil {
+		t.Fatalf("newBuilder: %v", err)
+	}
+
+	merged := make(map[string]*resolvedNode)
+	if err := b.flatten(main, "", merged); err != nil {
+		t.Fatalf("flatten: %v", err)
+	}
+
+	resolved := make(map[string]nodeSpec, len(merged))
+	for key, n := range merged {
+		spec, err := b.resolveExtends(n)
+		if err != nil {
+			t.Fatalf("resolveExtends(%s): %v", key, err)
+		}
+		resolved[key] = spec
+	}
+
+	if err := b.pullTransitive(merged, resolved); err != nil {
+		t.Fatalf("pullTransitive: %v", err)
+	}
+
+	if _, ok := resolved["root"]; !ok {
+		t.Errorf("expected root to be pulled in as mid's sibling dependency, got keys %v", keysOf(merged))
+	}
+	if _, ok := resolved["other1"]; ok {
+		t.Errorf("expected other1 not to be pulled in, it is never referenced")
+	}
+	if _, ok := resolved["other2"]; ok {
+		t.Errorf("expected other2 not to be pulled in, it is never referenced")
+	}
+}
+
+func keysOf(m map[string]*resolvedNode) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
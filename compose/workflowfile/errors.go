@@ -0,0 +1,45 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflowfile
+
+import "fmt"
+
+// LoadError carries the file and line of the offending node on top of the
+// underlying cause, so users can jump straight to the broken YAML entry.
+type LoadError struct {
+	File string
+	Line int
+	Node string
+	Err  error
+}
+
+// Error implements the error interface.
+func (e *LoadError) Error() string {
+	if e.Node != "" {
+		return fmt.Sprintf("%s:%d: node %q: %v", e.File, e.Line, e.Node, e.Err)
+	}
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+// Unwrap allows errors.Is/As to reach the underlying cause.
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+func newLoadErr(file string, line int, node string, err error) error {
+	return &LoadError{File: file, Line: line, Node: node, Err: err}
+}
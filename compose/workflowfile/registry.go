@@ -0,0 +1,103 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflowfile
+
+import (
+	"fmt"
+
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/indexer"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/components/retriever"
+	"github.com/cloudwego/eino/compose"
+)
+
+// addNode looks up spec.Component in the registry, asserts it to the
+// component interface spec.Type expects, and adds it to wf under key.
+func addNode[I, O any](b *builder, wf *compose.Workflow[I, O], key string, spec nodeSpec) (*compose.WorkflowNode, error) {
+	comp, ok := b.registry[spec.Component]
+	if !ok {
+		return nil, fmt.Errorf("component %q not found in registry", spec.Component)
+	}
+
+	switch spec.Type {
+	case "chatmodel":
+		cm, ok := comp.(model.ChatModel)
+		if !ok {
+			return nil, fmt.Errorf("component %q is not a model.ChatModel", spec.Component)
+		}
+		return wf.AddChatModelNode(key, cm), nil
+	case "template":
+		ct, ok := comp.(prompt.ChatTemplate)
+		if !ok {
+			return nil, fmt.Errorf("component %q is not a prompt.ChatTemplate", spec.Component)
+		}
+		return wf.AddChatTemplateNode(key, ct), nil
+	case "lambda":
+		lm, ok := comp.(*compose.Lambda)
+		if !ok {
+			return nil, fmt.Errorf("component %q is not a *compose.Lambda", spec.Component)
+		}
+		return wf.AddLambdaNode(key, lm), nil
+	case "retriever":
+		rt, ok := comp.(retriever.Retriever)
+		if !ok {
+			return nil, fmt.Errorf("component %q is not a retriever.Retriever", spec.Component)
+		}
+		return wf.AddRetrieverNode(key, rt), nil
+	case "embedding":
+		em, ok := comp.(embedding.Embedder)
+		if !ok {
+			return nil, fmt.Errorf("component %q is not an embedding.Embedder", spec.Component)
+		}
+		return wf.AddEmbeddingNode(key, em), nil
+	case "indexer":
+		ix, ok := comp.(indexer.Indexer)
+		if !ok {
+			return nil, fmt.Errorf("component %q is not an indexer.Indexer", spec.Component)
+		}
+		return wf.AddIndexerNode(key, ix), nil
+	case "loader":
+		ld, ok := comp.(document.Loader)
+		if !ok {
+			return nil, fmt.Errorf("component %q is not a document.Loader", spec.Component)
+		}
+		return wf.AddLoaderNode(key, ld), nil
+	case "transformer":
+		tr, ok := comp.(document.Transformer)
+		if !ok {
+			return nil, fmt.Errorf("component %q is not a document.Transformer", spec.Component)
+		}
+		return wf.AddDocumentTransformerNode(key, tr), nil
+	case "tools":
+		tn, ok := comp.(*compose.ToolsNode)
+		if !ok {
+			return nil, fmt.Errorf("component %q is not a *compose.ToolsNode", spec.Component)
+		}
+		return wf.AddToolsNode(key, tn), nil
+	case "graph":
+		gr, ok := comp.(compose.AnyGraph)
+		if !ok {
+			return nil, fmt.Errorf("component %q is not a compose.AnyGraph", spec.Component)
+		}
+		return wf.AddGraphNode(key, gr), nil
+	default:
+		return nil, fmt.Errorf("unknown node type %q", spec.Type)
+	}
+}
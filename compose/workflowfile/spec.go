@@ -0,0 +1,115 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package workflowfile
+
+import "gopkg.in/yaml.v3"
+
+// fileSpec is the raw, unmarshalled shape of a single workflow file, before
+// include/extends resolution.
+type fileSpec struct {
+	Include []includeSpec       `yaml:"include"`
+	Nodes   map[string]nodeSpec `yaml:"nodes"`
+	End     []mappingSpec       `yaml:"end"`
+
+	// line records the source line of each top-level node key, keyed the
+	// same way as Nodes, so errors can be reported with file:line context.
+	line map[string]int `yaml:"-"`
+}
+
+// includeSpec references another workflow file whose nodes should be merged
+// into the current one under an optional key prefix.
+type includeSpec struct {
+	Path   string `yaml:"path"`
+	Prefix string `yaml:"prefix"`
+}
+
+// nodeSpec is the YAML shape of a single `nodes:` entry.
+type nodeSpec struct {
+	// Type selects which Workflow.AddXxxNode method builds this node, e.g.
+	// "chatmodel", "template", "lambda", "retriever", "embedding", "indexer",
+	// "loader", "transformer", "tools" or "graph".
+	Type string `yaml:"type"`
+
+	// Component is the key used to look up the node's component in the
+	// caller-supplied registry.
+	Component string `yaml:"component"`
+
+	// Extends references another node, in the form "file.yaml#nodeKey" or
+	// just "nodeKey" for a node defined in the same file, whose
+	// Type/Component/Inputs are inherited and then overridden by whatever
+	// this node explicitly sets.
+	Extends string `yaml:"extends"`
+
+	Inputs []mappingSpec `yaml:"inputs"`
+
+	line int `yaml:"-"`
+}
+
+// mappingSpec is the YAML shape of one `inputs:`/`end:` entry, translating
+// directly into a *compose.Mapping built with NewMapping(...).From().To().
+type mappingSpec struct {
+	FromNode  string `yaml:"from_node"`
+	FromField string `yaml:"from_field"`
+	ToField   string `yaml:"to_field"`
+}
+
+// parseFile unmarshals raw YAML bytes into a fileSpec, additionally walking
+// the document node tree to record the source line of each node key.
+func parseFile(data []byte) (*fileSpec, error) {
+	var spec fileSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	spec.line = make(map[string]int, len(spec.Nodes))
+	if nodesKey, nodesVal := findMappingValue(&doc, "nodes"); nodesKey != nil {
+		_ = nodesKey
+		for i := 0; i+1 < len(nodesVal.Content); i += 2 {
+			key := nodesVal.Content[i]
+			spec.line[key.Value] = key.Line
+		}
+	}
+
+	for key, n := range spec.Nodes {
+		n.line = spec.line[key]
+		spec.Nodes[key] = n
+	}
+
+	return &spec, nil
+}
+
+// findMappingValue looks for `key: ...` directly under a YAML document or
+// mapping node and returns the key node and its value node.
+func findMappingValue(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright 2024 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package workflowfile loads a compose.Workflow from a YAML file, so that
+// node wiring can be expressed declaratively and shared between projects
+// instead of hand-written in Go.
+//
+// A workflow file declares its nodes and how they're wired together:
+//
+//	nodes:
+//	  tpl:
+//	    type: template
+//	    component: myTemplate
+//	    inputs:
+//	      - to_field: Query
+//	  model:
+//	    type: chatmodel
+//	    component: myModel
+//	    inputs:
+//	      - from_node: tpl
+//	end:
+//	  - from_node: model
+//
+// `component:` is resolved from a caller-supplied registry of already
+// constructed components, keyed by name. A file may `include:` other
+// workflow files (optionally under a `prefix:` to avoid node key
+// collisions), and an individual node may `extends:` a node defined in
+// this or another file, inheriting its type/component/inputs and
+// overriding only what it sets explicitly.
+package workflowfile
+
+import "github.com/cloudwego/eino/compose"
+
+// Load reads the workflow file at path, resolves its include/extends
+// directives, wires every node's component from registry, and returns a
+// fully-built *compose.Workflow[I, O] ready to Compile.
+//
+// registry maps the `component:` name used in the file to the already
+// constructed component instance (a model.ChatModel, prompt.ChatTemplate,
+// *compose.Lambda, etc., matching the node's `type:`).
+func Load[I, O any](path string, registry map[string]any) (*compose.Workflow[I, O], error) {
+	b, err := newBuilder(registry)
+	if err != nil {
+		return nil, err
+	}
+
+	wf := compose.NewWorkflow[I, O]()
+	if err = apply[I, O](b, wf, path); err != nil {
+		return nil, err
+	}
+
+	return wf, nil
+}